@@ -1,7 +1,9 @@
 package main
 
 import (
+	"context"
 	"log"
+	_ "time/tzdata" // タイムゾーンデータが無い実行環境でもユーザーのタイムゾーンを扱えるようにする
 
 	routes "okusuri-backend/internal"
 	"okusuri-backend/migrations"
@@ -15,8 +17,16 @@ func main() {
 	// マイグレーションの実行
 	migrations.RunMigrations(config.GetDB())
 
+	// リポジトリとサービスの初期化
+	deps := routes.NewDependencies()
+
+	// バックグラウンドジョブ（通知のスケジューラーを含む）の開始
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	routes.StartJobs(ctx, deps)
+
 	// Ginのルーターを作成
-	router := routes.SetupRoutes()
+	router := routes.SetupRoutes(deps)
 
 	// サーバーを起動
 	if err := router.Run(":8080"); err != nil {
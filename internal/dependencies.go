@@ -0,0 +1,64 @@
+package internal
+
+import (
+	"okusuri-backend/internal/repository"
+	"okusuri-backend/internal/service"
+	"okusuri-backend/pkg/clock"
+)
+
+// Dependencies はルーティングとバックグラウンドジョブで共有するリポジトリとサービス
+type Dependencies struct {
+	UserRepo         *repository.UserRepository
+	SessionRepo      *repository.SessionRepository
+	AccountRepo      *repository.AccountRepository
+	MedicationRepo   *repository.MedicationRepository
+	NotificationRepo *repository.NotificationRepository
+	RegimenRepo      *repository.RegimenRepository
+	HealthRepo       *repository.HealthRepository
+	PrescriptionRepo *repository.PrescriptionRepository
+	AppointmentRepo  *repository.AppointmentRepository
+
+	NotificationService *service.NotificationService
+	MedicationService   *service.MedicationService
+	HealthService       *service.HealthService
+	ReminderService     *service.ReminderService
+}
+
+// NewDependencies はリポジトリとサービスを初期化する
+func NewDependencies() *Dependencies {
+	// リポジトリの初期化
+	userRepo := repository.NewUserRepository()
+	sessionRepo := repository.NewSessionRepository(userRepo.GetDB())
+	accountRepo := repository.NewAccountRepository(userRepo.GetDB())
+	medicationRepo := repository.NewMedicationRepository()
+	notificationRepo := repository.NewNotificationRepository()
+	regimenRepo := repository.NewRegimenRepository()
+	healthRepo := repository.NewHealthRepository()
+	prescriptionRepo := repository.NewPrescriptionRepository()
+	appointmentRepo := repository.NewAppointmentRepository()
+
+	// サービスの初期化
+	clk := clock.New()
+	notificationService := service.NewNotificationService(clk)
+	medicationService := service.NewMedicationService(medicationRepo, regimenRepo, userRepo, clk)
+	healthService := service.NewHealthService(healthRepo, medicationRepo, clk)
+	reminderService := service.NewReminderService(
+		userRepo, notificationRepo, medicationRepo, prescriptionRepo, medicationService, notificationService,
+	)
+
+	return &Dependencies{
+		UserRepo:            userRepo,
+		SessionRepo:         sessionRepo,
+		AccountRepo:         accountRepo,
+		MedicationRepo:      medicationRepo,
+		NotificationRepo:    notificationRepo,
+		RegimenRepo:         regimenRepo,
+		HealthRepo:          healthRepo,
+		PrescriptionRepo:    prescriptionRepo,
+		AppointmentRepo:     appointmentRepo,
+		NotificationService: notificationService,
+		MedicationService:   medicationService,
+		HealthService:       healthService,
+		ReminderService:     reminderService,
+	}
+}
@@ -0,0 +1,12 @@
+package dto
+
+import "time"
+
+// 受診予定の登録・更新リクエスト
+type AppointmentRequest struct {
+	ScheduledAt         time.Time `json:"scheduledAt" binding:"required"`                                    // 受診日時
+	Clinic              string    `json:"clinic" binding:"required,max=200"`                                 // 医療機関
+	Purpose             string    `json:"purpose" binding:"max=200"`                                         // 受診の目的
+	Note                string    `json:"note" binding:"max=1000"`                                           // メモ
+	RemindBeforeMinutes *int      `json:"remindBeforeMinutes,omitempty" binding:"omitempty,min=0,max=20160"` // 何分前にリマインダーを送るか（省略時は前日、0は送らない）
+}
@@ -0,0 +1,17 @@
+package dto
+
+// 周期の1フェーズ（服用期間または休薬期間）
+type CyclePhase struct {
+	Phase        string `json:"phase"`        // フェーズ（active / rest）
+	StartDate    string `json:"startDate"`    // 開始日（YYYY-MM-DD、ユーザーのタイムゾーン）
+	EndDate      string `json:"endDate"`      // 終了日（終了日を含む。進行中の休薬期間は終了予定日）
+	LengthDays   int    `json:"lengthDays"`   // 日数
+	BleedingDays int    `json:"bleedingDays"` // 出血が記録された日数
+	Forced       bool   `json:"forced"`       // 最大連続服用日数による強制休薬か（休薬期間のみ）
+	Ongoing      bool   `json:"ongoing"`      // 現在進行中のフェーズか
+}
+
+// 周期履歴レスポンス
+type CycleHistoryResponse struct {
+	Phases []CyclePhase `json:"phases"` // 古い順
+}
@@ -0,0 +1,18 @@
+package dto
+
+// 飲み忘れ・服用遅れ時の対処の重要度
+const (
+	GuidanceLevelInfo     = "info"     // いつも通りの服用で問題ない
+	GuidanceLevelWarning  = "warning"  // 対処が必要
+	GuidanceLevelCritical = "critical" // 避妊効果が低下している可能性がある
+)
+
+// 飲み忘れ・服用遅れ時の対処ガイダンス
+type GuidanceResponse struct {
+	Level                   string   `json:"level"`                   // 重要度
+	HoursLate               float64  `json:"hoursLate"`               // 予定時刻からの遅れ（時間）
+	MissedDays              int      `json:"missedDays"`              // 連続した飲み忘れの日数
+	Message                 string   `json:"message"`                 // 状況の説明
+	Actions                 []string `json:"actions"`                 // 取るべき行動
+	BackupContraceptionDays int      `json:"backupContraceptionDays"` // 他の避妊法を併用すべき日数（不要な場合は0）
+}
@@ -0,0 +1,49 @@
+package dto
+
+import "time"
+
+// 体調の記録の種類
+const (
+	HealthMetricWeight      = "weight"      // 体重（kg）
+	HealthMetricSystolicBP  = "systolicBp"  // 収縮期血圧（mmHg）
+	HealthMetricDiastolicBP = "diastolicBp" // 拡張期血圧（mmHg）
+	HealthMetricMood        = "mood"        // 気分（1〜5）
+)
+
+// 体調の記録の登録・更新リクエスト
+type HealthEntryRequest struct {
+	MedicationID *uint      `json:"medicationId,omitempty"`                                       // 関連する薬
+	RecordedAt   *time.Time `json:"recordedAt,omitempty"`                                         // 省略時は現在日時
+	WeightKg     *float64   `json:"weightKg,omitempty" binding:"omitempty,gt=0,max=500"`          // 体重（kg）
+	SystolicBP   *int       `json:"systolicBp,omitempty" binding:"omitempty,min=40,max=300"`      // 収縮期血圧（mmHg）
+	DiastolicBP  *int       `json:"diastolicBp,omitempty" binding:"omitempty,min=20,max=200"`     // 拡張期血圧（mmHg）
+	MoodScore    *int       `json:"moodScore,omitempty" binding:"omitempty,min=1,max=5"`          // 気分（1〜5）
+	SideEffects  []string   `json:"sideEffects,omitempty" binding:"omitempty,max=20,dive,max=50"` // 副作用
+	Note         string     `json:"note" binding:"max=1000"`                                      // メモ
+}
+
+// 体調の傾向レスポンス
+type HealthTrendResponse struct {
+	PeriodDays  int                     `json:"periodDays"`            // 比較する期間（日）
+	Metrics     []HealthMetricTrend     `json:"metrics"`               // 直近の期間とその前の期間の比較
+	SideEffects map[string]int          `json:"sideEffects"`           // 直近の期間に記録された副作用の回数
+	Medications []MedicationHealthTrend `json:"medications,omitempty"` // 薬の服用開始前後の比較
+}
+
+// 体調の記録の種類ごとの比較
+type HealthMetricTrend struct {
+	Metric          string   `json:"metric"`                    // 記録の種類
+	RecentAverage   *float64 `json:"recentAverage,omitempty"`   // 直近（服用開始後）の平均（記録がない場合は省略）
+	PreviousAverage *float64 `json:"previousAverage,omitempty"` // その前（服用開始前）の平均（記録がない場合は省略）
+	Change          *float64 `json:"change,omitempty"`          // 平均の変化（両方の記録がある場合のみ）
+	RecentCount     int      `json:"recentCount"`               // 直近（服用開始後）の記録数
+	PreviousCount   int      `json:"previousCount"`             // その前（服用開始前）の記録数
+}
+
+// 薬の服用開始前後の体調の比較
+type MedicationHealthTrend struct {
+	MedicationID   uint                `json:"medicationId"`
+	MedicationName string              `json:"medicationName"`
+	StartedAt      time.Time           `json:"startedAt"` // 服用開始日時（薬の登録日時）
+	Metrics        []HealthMetricTrend `json:"metrics"`
+}
@@ -1,9 +1,35 @@
 package dto
 
-import "time"
+import (
+	"okusuri-backend/internal/model"
+	"time"
+)
 
 // 服用記録リクエスト
 type MedicationLogRequest struct {
-	HasBleeding bool       `json:"hasBleeding"`
-	Date        *time.Time `json:"date,omitempty"` // 指定された日付（省略時は現在日時）
+	MedicationID  *uint      `json:"medicationId,omitempty"`                                                                                           // 服用した薬（省略時は従来の単一の薬）
+	HasBleeding   *bool      `json:"hasBleeding,omitempty"`                                                                                            // 旧クライアント向け（bleedingLevelが指定された場合は無視する）
+	BleedingLevel *string    `json:"bleedingLevel,omitempty" binding:"omitempty,oneof=none spotting light medium heavy"`                               // 出血の程度
+	PainScore     *int       `json:"painScore,omitempty" binding:"omitempty,min=0,max=10"`                                                             // 痛みの強さ（0〜10）
+	Symptoms      []string   `json:"symptoms,omitempty" binding:"omitempty,dive,oneof=headache nausea mood cramps breast_tenderness fatigue bloating"` // 症状の一覧
+	Note          *string    `json:"note,omitempty" binding:"omitempty,max=1000"`                                                                      // メモ（1000文字まで）
+	Tags          []string   `json:"tags,omitempty" binding:"omitempty,max=20,dive,max=50"`                                                            // タグ（20個まで、各50文字まで）
+	Date          *time.Time `json:"date,omitempty"`                                                                                                   // 指定された日付（省略時は現在日時）
+}
+
+// 服用記録一覧の取得条件
+type MedicationLogListQuery struct {
+	From   string `form:"from"`                                     // 開始日（YYYY-MM-DDまたはRFC3339）
+	To     string `form:"to"`                                       // 終了日（YYYY-MM-DDの場合はその日を含む）
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=200"`  // 取得件数（cursorのみ指定した場合は50件、どちらも省略時は全件）
+	Cursor string `form:"cursor"`                                   // 前回のレスポンスのnextCursor
+	Order  string `form:"order" binding:"omitempty,oneof=asc desc"` // 並び順（省略時はdesc）
+	Q      string `form:"q"`                                        // メモの部分一致検索
+	Tag    string `form:"tag"`                                      // タグでの絞り込み
+}
+
+// 服用記録一覧レスポンス
+type MedicationLogListResponse struct {
+	Logs       []model.MedicationLog `json:"logs"`
+	NextCursor string                `json:"nextCursor,omitempty"` // 次のページがない場合は空
 }
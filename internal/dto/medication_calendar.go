@@ -0,0 +1,18 @@
+package dto
+
+// 服薬カレンダーレスポンス
+type MedicationCalendarResponse struct {
+	Month string        `json:"month"` // 対象月（YYYY-MM）
+	Days  []CalendarDay `json:"days"`  // 月の各日の状態（1日から月末まで）
+}
+
+// カレンダーの1日分の状態
+type CalendarDay struct {
+	Date          string `json:"date"`          // 日付（YYYY-MM-DD、ユーザーのタイムゾーン）
+	Taken         bool   `json:"taken"`         // 服用記録があるか
+	Missed        bool   `json:"missed"`        // 飲み忘れとして記録されているか（服用記録がある場合はfalse）
+	IsRestPeriod  bool   `json:"isRestPeriod"`  // 休薬期間（偽薬期間を含む）か
+	HasBleeding   bool   `json:"hasBleeding"`   // 休薬の判定に数える出血の記録があるか
+	BleedingLevel string `json:"bleedingLevel"` // 記録された最も多い出血の程度
+	LogCount      int    `json:"logCount"`      // 服用記録の件数（飲み忘れの自動記録を除く）
+}
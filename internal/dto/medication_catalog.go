@@ -0,0 +1,31 @@
+package dto
+
+import "okusuri-backend/internal/model"
+
+// 薬の登録・更新リクエスト
+type MedicationRequest struct {
+	Name     string `json:"name" binding:"required"`
+	Dosage   string `json:"dosage"`
+	Form     string `json:"form"`
+	Schedule string `json:"schedule" binding:"omitempty,oneof=regimen daily"` // 省略時はregimen
+	Color    string `json:"color"`
+	Icon     string `json:"icon"`
+	IsActive *bool  `json:"isActive,omitempty"` // 省略時は服用中
+
+	ScheduledTime    string `json:"scheduledTime"`                                     // 服用予定時刻（HH:MM、省略時は予定時刻なし）
+	WindowMinutes    *int   `json:"windowMinutes,omitempty" binding:"omitempty,min=0"` // 省略時は60分
+	LateLimitMinutes int    `json:"lateLimitMinutes" binding:"min=0"`                  // 0の場合は飲み忘れ判定を行わない
+
+	// 在庫（省略した項目は変更しない）
+	PillsPerPack         *int        `json:"pillsPerPack,omitempty" binding:"omitempty,min=0,max=200"`         // 1シートの錠数（0は在庫を管理しない）
+	PacksOnHand          *int        `json:"packsOnHand,omitempty" binding:"omitempty,min=0"`                  // 未開封のシート数
+	CurrentPackStartDate *model.Date `json:"currentPackStartDate,omitempty"`                                   // 服用中のシートを開始した日（YYYY-MM-DD）
+	CurrentPackPillsLeft *int        `json:"currentPackPillsLeft,omitempty" binding:"omitempty,min=0,max=200"` // 服用中のシートの残り錠数
+	RefillThresholdPills *int        `json:"refillThresholdPills,omitempty" binding:"omitempty,min=0"`         // 残り錠数がこれを下回ると補充を促す（省略時は7錠）
+}
+
+// シート購入リクエスト
+type PackPurchaseRequest struct {
+	Packs        int  `json:"packs" binding:"required,min=1,max=100"`                   // 購入したシート数
+	PillsPerPack *int `json:"pillsPerPack,omitempty" binding:"omitempty,min=1,max=200"` // 1シートの錠数（省略時は登録済みの値）
+}
@@ -0,0 +1,23 @@
+package dto
+
+// 統計の集計期間
+const (
+	StatsPeriod30Days = "30d"
+	StatsPeriod90Days = "90d"
+	StatsPeriod1Year  = "1y"
+)
+
+// 服薬統計レスポンス
+type MedicationStatsResponse struct {
+	Period                      string  `json:"period"`                      // 集計期間（30d / 90d / 1y）
+	From                        string  `json:"from"`                        // 集計開始日（YYYY-MM-DD）
+	To                          string  `json:"to"`                          // 集計終了日（YYYY-MM-DD、今日）
+	AdherenceRate               float64 `json:"adherenceRate"`               // 服用率（%、休薬期間を除いた服用すべき日に対する服用日の割合）
+	TakenDays                   int     `json:"takenDays"`                   // 服用した日数
+	ExpectedDays                int     `json:"expectedDays"`                // 服用すべき日数
+	LongestStreak               int     `json:"longestStreak"`               // 最長連続服用日数
+	AverageCycleLength          float64 `json:"averageCycleLength"`          // 平均周期日数（休薬開始から次の休薬開始まで）
+	AverageBleedingDaysPerCycle float64 `json:"averageBleedingDaysPerCycle"` // 1周期あたりの平均出血日数
+	RestPeriodCount             int     `json:"restPeriodCount"`             // 休薬期間の回数
+	MissedDoseCount             int     `json:"missedDoseCount"`             // 飲み忘れの回数
+}
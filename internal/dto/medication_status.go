@@ -1,9 +1,56 @@
 package dto
 
+import "time"
+
+// 服薬フェーズ
+const (
+	PhaseActive = "active" // 服用期間
+	PhaseRest   = "rest"   // 休薬期間（偽薬期間を含む）
+)
+
 // 服薬ステータスレスポンス
 type MedicationStatusResponse struct {
-	CurrentStreak           int  `json:"currentStreak"`           // 現在の連続服用日数
-	IsRestPeriod            bool `json:"isRestPeriod"`            // 休薬期間中かどうか
-	RestDaysLeft            int  `json:"restDaysLeft"`            // 休薬期間の残り日数（休薬期間中の場合）
-	ConsecutiveBleedingDays int  `json:"consecutiveBleedingDays"` // 連続出血日数
+	MedicationID            *uint                `json:"medicationId,omitempty"`       // 薬ごとのステータスの場合の薬ID
+	MedicationName          string               `json:"medicationName,omitempty"`     // 薬ごとのステータスの場合の薬の名前
+	CurrentStreak           int                  `json:"currentStreak"`                // 現在の連続服用日数
+	IsRestPeriod            bool                 `json:"isRestPeriod"`                 // 休薬期間中かどうか
+	RestDaysLeft            int                  `json:"restDaysLeft"`                 // 休薬期間の残り日数（休薬期間中の場合）
+	ConsecutiveBleedingDays int                  `json:"consecutiveBleedingDays"`      // 連続出血日数
+	RegimenType             string               `json:"regimenType"`                  // レジメンの種類
+	Phase                   string               `json:"phase"`                        // 現在のフェーズ（active / rest）
+	DayInPack               int                  `json:"dayInPack"`                    // 現在のフェーズまたはシート内での日数
+	NextPhaseChangeAt       *time.Time           `json:"nextPhaseChangeAt,omitempty"`  // 次にフェーズが切り替わる日時（予測できない場合は省略）
+	RestAllowed             bool                 `json:"restAllowed"`                  // 出血による休薬が許可されているか（最低連続服用日数を満たしているか）
+	ForcedRestDueAt         *time.Time           `json:"forcedRestDueAt,omitempty"`    // 最大連続服用日数に達して強制休薬となる日（制限なしの場合は省略）
+	Adherence               *AdherenceSummary    `json:"adherence,omitempty"`          // 予定時刻に対する服用状況（予定時刻のある記録がない場合は省略）
+	MissedYesterday         bool                 `json:"missedYesterday"`              // 昨日が飲み忘れとして記録されているか
+	NextRestPrediction      *RestPrediction      `json:"nextRestPrediction,omitempty"` // 次の出血による休薬の予測（予測できない場合は省略）
+	HealthTrends            *HealthTrendResponse `json:"healthTrends,omitempty"`       // 体調の記録の傾向（全体のステータスのみ）
+
+	Medications []MedicationStatusResponse `json:"medications,omitempty"` // 服用中の薬ごとのステータス
+}
+
+// 予定時刻に対する服用状況の集計
+type AdherenceSummary struct {
+	PeriodDays  int     `json:"periodDays"`  // 集計期間（日）
+	OnTimeCount int     `json:"onTimeCount"` // 時間通りに服用した回数
+	LateCount   int     `json:"lateCount"`   // 遅れて服用した回数
+	MissedCount int     `json:"missedCount"` // 飲み忘れ扱いの回数
+	OnTimeRate  float64 `json:"onTimeRate"`  // 時間通りに服用した割合（0〜1）
+}
+
+// 予測の信頼度
+const (
+	PredictionConfidenceLow    = "low"
+	PredictionConfidenceMedium = "medium"
+	PredictionConfidenceHigh   = "high"
+)
+
+// 過去の周期から予測した次の休薬開始日
+type RestPrediction struct {
+	PredictedStartAt time.Time `json:"predictedStartAt"` // 最も可能性の高い休薬開始日
+	EarliestStartAt  time.Time `json:"earliestStartAt"`  // 予測範囲の開始日
+	LatestStartAt    time.Time `json:"latestStartAt"`    // 予測範囲の終了日
+	SampleCycles     int       `json:"sampleCycles"`     // 予測に用いた過去の周期の数
+	Confidence       string    `json:"confidence"`       // 信頼度（low / medium / high）
 }
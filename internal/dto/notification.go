@@ -5,4 +5,16 @@ type RegisterNotificationSettingRequest struct {
 	Subscription string `json:"subscription" binding:"required"` // FCMTokenをSubscriptionに変更
 	IsEnabled    bool   `json:"isEnabled" binding:"required"`
 	Platform     string `json:"platform" binding:"required"`
+
+	ReminderTimes           []string `json:"reminderTimes" binding:"omitempty,max=10,dive,datetime=15:04"`                      // 通知時刻（HH:MM、省略時は服用予定時刻）
+	ReminderWeekdays        []string `json:"reminderWeekdays" binding:"omitempty,max=7,dive,oneof=sun mon tue wed thu fri sat"` // 通知する曜日（省略時は毎日）
+	Timezone                *string  `json:"timezone,omitempty"`                                                                // 通知時刻のタイムゾーン（IANAタイムゾーン名、省略時はユーザーのタイムゾーン）
+	FollowUpIntervalMinutes *int     `json:"followUpIntervalMinutes,omitempty" binding:"omitempty,min=5,max=240"`               // 再通知の間隔（分、省略時は30分）
+	FollowUpMaxCount        *int     `json:"followUpMaxCount,omitempty" binding:"omitempty,min=0,max=10"`                       // 再通知の最大回数（省略時は2回、0は再通知しない）
+}
+
+// 通知のアクションリクエスト（通知に含まれるアクショントークンで認証する）
+type NotificationActionRequest struct {
+	Token  string `json:"token" binding:"required"`
+	Action string `json:"action" binding:"required,oneof=taken taken_bleeding snooze"`
 }
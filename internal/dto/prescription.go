@@ -0,0 +1,14 @@
+package dto
+
+import "okusuri-backend/internal/model"
+
+// 処方箋の登録・更新リクエスト
+type PrescriptionRequest struct {
+	MedicationID *uint      `json:"medicationId,omitempty"`       // 処方された薬
+	Prescriber   string     `json:"prescriber" binding:"max=100"` // 処方した医師
+	Clinic       string     `json:"clinic" binding:"max=200"`     // 医療機関
+	IssuedOn     model.Date `json:"issuedOn" binding:"required"`  // 発行日（YYYY-MM-DD）
+	ExpiresOn    model.Date `json:"expiresOn" binding:"required"` // 有効期限（YYYY-MM-DD、この日まで有効）
+	RefillsLeft  int        `json:"refillsLeft" binding:"min=0"`  // 残りのリフィル回数
+	Note         string     `json:"note" binding:"max=1000"`
+}
@@ -0,0 +1,17 @@
+package dto
+
+import "okusuri-backend/internal/model"
+
+// レジメン登録・更新リクエスト
+type RegimenRequest struct {
+	Type                  string      `json:"type" binding:"omitempty,oneof=flexible cyclic continuous"` // 省略時はflexible
+	BleedingDaysThreshold int         `json:"bleedingDaysThreshold" binding:"min=0"`                     // 0の場合はデフォルト値
+	RestPeriodDays        int         `json:"restPeriodDays" binding:"min=0"`                            // 0の場合はデフォルト値
+	MinActiveDays         int         `json:"minActiveDays" binding:"min=0"`
+	MaxContinuousDays     int         `json:"maxContinuousDays" binding:"min=0"`
+	PackActiveDays        int         `json:"packActiveDays" binding:"min=0"`                                         // cyclicの場合は必須
+	PackBreakDays         int         `json:"packBreakDays" binding:"min=0"`                                          // cyclicの場合は必須
+	PackStartDate         *model.Date `json:"packStartDate,omitempty"`                                                // シート開始日（YYYY-MM-DD）
+	MinBleedingLevel      string      `json:"minBleedingLevel" binding:"omitempty,oneof=spotting light medium heavy"` // 省略時はspotting（全ての出血を数える）
+	PillType              string      `json:"pillType" binding:"omitempty,oneof=combined progestin_only"`             // 省略時はcombined
+}
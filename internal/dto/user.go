@@ -0,0 +1,6 @@
+package dto
+
+// タイムゾーン更新リクエスト
+type UpdateTimezoneRequest struct {
+	Timezone string `json:"timezone" binding:"required"` // IANAタイムゾーン名（例: Asia/Tokyo）
+}
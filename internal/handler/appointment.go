@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"net/http"
+	"okusuri-backend/internal/dto"
+	"okusuri-backend/internal/model"
+	"okusuri-backend/internal/repository"
+	"okusuri-backend/pkg/helper"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AppointmentHandler struct {
+	appointmentRepo *repository.AppointmentRepository
+}
+
+func NewAppointmentHandler(appointmentRepo *repository.AppointmentRepository) *AppointmentHandler {
+	return &AppointmentHandler{
+		appointmentRepo: appointmentRepo,
+	}
+}
+
+// GetAppointments は受診予定の一覧を取得するハンドラー（upcoming=trueの場合は今後の予定のみ）
+func (h *AppointmentHandler) GetAppointments(c *gin.Context) {
+	// ユーザーIDを取得
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	var from *time.Time
+	if c.Query("upcoming") == "true" {
+		now := time.Now()
+		from = &now
+	}
+
+	appointments, err := h.appointmentRepo.GetByUserID(userID, from)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get appointments"})
+		return
+	}
+
+	c.JSON(http.StatusOK, appointments)
+}
+
+// RegisterAppointment は受診予定を登録するハンドラー
+func (h *AppointmentHandler) RegisterAppointment(c *gin.Context) {
+	// ユーザーIDを取得
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	var req dto.AppointmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	appointment := model.Appointment{
+		UserID:              userID,
+		RemindBeforeMinutes: model.DefaultAppointmentRemindBeforeMinutes,
+	}
+	applyAppointmentRequest(&appointment, req)
+
+	if err := h.appointmentRepo.Create(&appointment); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to register appointment"})
+		return
+	}
+
+	c.JSON(http.StatusOK, appointment)
+}
+
+// GetAppointmentByID は指定されたIDの受診予定を取得するハンドラー
+func (h *AppointmentHandler) GetAppointmentByID(c *gin.Context) {
+	// ユーザーIDを取得
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	// URLからIDパラメータを取得
+	appointmentID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid appointment ID"})
+		return
+	}
+
+	appointment, err := h.appointmentRepo.GetByID(userID, uint(appointmentID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "appointment not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, appointment)
+}
+
+// UpdateAppointment は指定されたIDの受診予定を更新するハンドラー
+func (h *AppointmentHandler) UpdateAppointment(c *gin.Context) {
+	// ユーザーIDを取得
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	// URLからIDパラメータを取得
+	appointmentID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid appointment ID"})
+		return
+	}
+
+	var req dto.AppointmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	appointment, err := h.appointmentRepo.GetByID(userID, uint(appointmentID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "appointment not found"})
+		return
+	}
+
+	applyAppointmentRequest(appointment, req)
+
+	if err := h.appointmentRepo.Update(appointment); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update appointment"})
+		return
+	}
+
+	c.JSON(http.StatusOK, appointment)
+}
+
+// DeleteAppointment は指定されたIDの受診予定を削除するハンドラー
+func (h *AppointmentHandler) DeleteAppointment(c *gin.Context) {
+	// ユーザーIDを取得
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	// URLからIDパラメータを取得
+	appointmentID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid appointment ID"})
+		return
+	}
+
+	err = h.appointmentRepo.Delete(userID, uint(appointmentID))
+	if err != nil {
+		if err.Error() == "appointment not found or user not authorized" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete appointment"})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.BaseResponse{
+		Success: true,
+		Message: "appointment deleted successfully",
+	})
+}
+
+// applyAppointmentRequest はリクエストの内容を受診予定に反映する
+// 受診日時やリマインダーのタイミングが変わった場合は、リマインダーを送り直す
+func applyAppointmentRequest(appointment *model.Appointment, req dto.AppointmentRequest) {
+	remindBefore := appointment.RemindBeforeMinutes
+	if req.RemindBeforeMinutes != nil {
+		remindBefore = *req.RemindBeforeMinutes
+	}
+
+	if !appointment.ScheduledAt.Equal(req.ScheduledAt) || appointment.RemindBeforeMinutes != remindBefore {
+		appointment.ReminderSentAt = nil
+	}
+
+	appointment.ScheduledAt = req.ScheduledAt
+	appointment.Clinic = req.Clinic
+	appointment.Purpose = req.Purpose
+	appointment.Note = req.Note
+	appointment.RemindBeforeMinutes = remindBefore
+}
@@ -0,0 +1,250 @@
+package handler
+
+import (
+	"net/http"
+	"okusuri-backend/internal/dto"
+	"okusuri-backend/internal/model"
+	"okusuri-backend/internal/repository"
+	"okusuri-backend/internal/service"
+	"okusuri-backend/pkg/helper"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HealthHandler struct {
+	healthRepo     *repository.HealthRepository
+	medicationRepo *repository.MedicationRepository
+	healthSvc      *service.HealthService
+}
+
+func NewHealthHandler(
+	healthRepo *repository.HealthRepository,
+	medicationRepo *repository.MedicationRepository,
+	healthSvc *service.HealthService,
+) *HealthHandler {
+	return &HealthHandler{
+		healthRepo:     healthRepo,
+		medicationRepo: medicationRepo,
+		healthSvc:      healthSvc,
+	}
+}
+
+// GetEntries は体調の記録一覧を取得するハンドラー（from, toはRFC3339で指定可能）
+func (h *HealthHandler) GetEntries(c *gin.Context) {
+	// ユーザーIDを取得
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	var from, to *time.Time
+	if fromStr := c.Query("from"); fromStr != "" {
+		t, err := time.Parse(time.RFC3339, fromStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from"})
+			return
+		}
+		from = &t
+	}
+	if toStr := c.Query("to"); toStr != "" {
+		t, err := time.Parse(time.RFC3339, toStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to"})
+			return
+		}
+		to = &t
+	}
+
+	entries, err := h.healthRepo.GetByUserID(userID, from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get health entries"})
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// RegisterEntry は体調の記録を登録するハンドラー
+func (h *HealthHandler) RegisterEntry(c *gin.Context) {
+	// ユーザーIDを取得
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	req, ok := h.bindEntryRequest(c, userID)
+	if !ok {
+		return
+	}
+
+	entry := model.HealthEntry{UserID: userID}
+	h.applyEntryRequest(&entry, req)
+
+	if err := h.healthRepo.Create(&entry); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to register health entry"})
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// GetEntryByID は指定されたIDの体調の記録を取得するハンドラー
+func (h *HealthHandler) GetEntryByID(c *gin.Context) {
+	// ユーザーIDを取得
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	// URLからIDパラメータを取得
+	entryID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid health entry ID"})
+		return
+	}
+
+	entry, err := h.healthRepo.GetByID(userID, uint(entryID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "health entry not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// UpdateEntry は指定されたIDの体調の記録を更新するハンドラー
+func (h *HealthHandler) UpdateEntry(c *gin.Context) {
+	// ユーザーIDを取得
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	// URLからIDパラメータを取得
+	entryID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid health entry ID"})
+		return
+	}
+
+	req, ok := h.bindEntryRequest(c, userID)
+	if !ok {
+		return
+	}
+
+	entry, err := h.healthRepo.GetByID(userID, uint(entryID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "health entry not found"})
+		return
+	}
+
+	h.applyEntryRequest(entry, req)
+
+	if err := h.healthRepo.Update(entry); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update health entry"})
+		return
+	}
+
+	c.JSON(http.StatusOK, entry)
+}
+
+// DeleteEntry は指定されたIDの体調の記録を削除するハンドラー
+func (h *HealthHandler) DeleteEntry(c *gin.Context) {
+	// ユーザーIDを取得
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	// URLからIDパラメータを取得
+	entryID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid health entry ID"})
+		return
+	}
+
+	err = h.healthRepo.Delete(userID, uint(entryID))
+	if err != nil {
+		if err.Error() == "health entry not found or user not authorized" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete health entry"})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.BaseResponse{
+		Success: true,
+		Message: "health entry deleted successfully",
+	})
+}
+
+// GetTrends は体調の傾向を取得するハンドラー
+func (h *HealthHandler) GetTrends(c *gin.Context) {
+	// ユーザーIDを取得
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	trends, err := h.healthSvc.GetTrends(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get health trends"})
+		return
+	}
+
+	c.JSON(http.StatusOK, trends)
+}
+
+// bindEntryRequest はリクエストボディをバインドして検証する
+func (h *HealthHandler) bindEntryRequest(c *gin.Context, userID string) (*dto.HealthEntryRequest, bool) {
+	var req dto.HealthEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return nil, false
+	}
+
+	if req.WeightKg == nil && req.SystolicBP == nil && req.DiastolicBP == nil &&
+		req.MoodScore == nil && len(req.SideEffects) == 0 && strings.TrimSpace(req.Note) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "at least one health metric, side effect or note is required"})
+		return nil, false
+	}
+
+	// 薬が指定されている場合は、ユーザーが登録した薬か確認する
+	if req.MedicationID != nil {
+		if _, err := h.medicationRepo.GetMedicationByID(userID, *req.MedicationID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "medication not found"})
+			return nil, false
+		}
+	}
+
+	return &req, true
+}
+
+// applyEntryRequest はリクエストの内容を体調の記録に反映する
+func (h *HealthHandler) applyEntryRequest(entry *model.HealthEntry, req *dto.HealthEntryRequest) {
+	entry.MedicationID = req.MedicationID
+	entry.WeightKg = req.WeightKg
+	entry.SystolicBP = req.SystolicBP
+	entry.DiastolicBP = req.DiastolicBP
+	entry.MoodScore = req.MoodScore
+	entry.SideEffects = normalizeTags(req.SideEffects)
+	entry.Note = strings.TrimSpace(req.Note)
+
+	// 記録日時が指定されていない場合は現在日時（更新時は既存の日時を維持）
+	switch {
+	case req.RecordedAt != nil:
+		entry.RecordedAt = *req.RecordedAt
+	case entry.RecordedAt.IsZero():
+		entry.RecordedAt = h.healthSvc.Now()
+	}
+}
@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"encoding/base64"
+	"fmt"
 	"net/http"
 	"okusuri-backend/internal/dto"
 	"okusuri-backend/internal/model"
@@ -8,17 +10,33 @@ import (
 	"okusuri-backend/internal/service"
 	"okusuri-backend/pkg/helper"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+// 服用予定時刻の前後何分までを時間通りとするかのデフォルト値
+const defaultIntakeWindowMinutes = 60
+
+// 服用記録一覧で一度に返す件数のデフォルト値
+const defaultLogListLimit = 50
+
 type MedicationHandler struct {
 	medicationRepo *repository.MedicationRepository
+	medicationSvc  *service.MedicationService
+	healthSvc      *service.HealthService
 }
 
-func NewMedicationHandler(medicationRepo *repository.MedicationRepository) *MedicationHandler {
+func NewMedicationHandler(
+	medicationRepo *repository.MedicationRepository,
+	medicationSvc *service.MedicationService,
+	healthSvc *service.HealthService,
+) *MedicationHandler {
 	return &MedicationHandler{
 		medicationRepo: medicationRepo,
+		medicationSvc:  medicationSvc,
+		healthSvc:      healthSvc,
 	}
 }
 
@@ -37,16 +55,43 @@ func (h *MedicationHandler) RegisterLog(c *gin.Context) {
 		return
 	}
 
+	// 薬が指定されている場合は、ユーザーが登録した薬か確認する
+	var medication *model.Medication
+	if req.MedicationID != nil {
+		medication, err = h.medicationRepo.GetMedicationByID(userID, *req.MedicationID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "medication not found"})
+			return
+		}
+	}
+
 	medicationLog := model.MedicationLog{
-		UserID:      userID,
-		HasBleeding: req.HasBleeding,
+		UserID:       userID,
+		MedicationID: req.MedicationID,
+		CreatedAt:    h.medicationSvc.Now(),
 	}
+	applyLogEntry(&medicationLog, req)
 
 	// 日付が指定されている場合は、その日付を使用
 	if req.Date != nil {
 		medicationLog.CreatedAt = *req.Date
 	}
 
+	loc, err := h.medicationSvc.GetLocation(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get user timezone"})
+		return
+	}
+
+	// 予定時刻のある薬の場合は服用タイミングを判定する
+	if medication != nil {
+		if intake := service.ClassifyIntake(*medication, medicationLog.CreatedAt.In(loc)); intake != nil {
+			medicationLog.IntakeStatus = intake.Status
+			medicationLog.ScheduledAt = &intake.ScheduledAt
+			medicationLog.DelayMinutes = int(intake.Delay.Minutes())
+		}
+	}
+
 	// リポジトリを直接呼び出す
 	err = h.medicationRepo.RegisterLog(userID, medicationLog)
 	if err != nil {
@@ -54,7 +99,34 @@ func (h *MedicationHandler) RegisterLog(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "medication log registered successfully"})
+	// 過去の日付で記録した場合は、その日の飲み忘れの自動記録を取り消す
+	takenAt := medicationLog.CreatedAt.In(loc)
+	day := time.Date(takenAt.Year(), takenAt.Month(), takenAt.Day(), 0, 0, 0, 0, loc)
+	if err := h.medicationRepo.DeleteMissedLogs(userID, medicationLog.MedicationID, day, day.AddDate(0, 0, 1)); err != nil {
+		fmt.Printf("飲み忘れの記録の削除に失敗: %v\n", err)
+	}
+
+	response := gin.H{"message": "medication log registered successfully"}
+
+	// 在庫を管理している薬の場合は在庫を減らす（失敗しても服用記録は登録済みのため成功とする）
+	if inventory := h.inventoryMedication(userID, medicationLog.MedicationID); inventory != nil {
+		updated, consumeErr := h.medicationRepo.ConsumePill(userID, inventory.ID, takenAt)
+		if consumeErr != nil {
+			fmt.Printf("在庫の更新に失敗: %v\n", consumeErr)
+		} else {
+			response["remainingPills"] = updated.RemainingPills()
+		}
+	}
+
+	// 遅れて服用した場合や飲み忘れの後の場合は対処ガイダンスを返す
+	guidance, err := h.medicationSvc.GetLogGuidance(userID, medicationLog)
+	if err != nil {
+		fmt.Printf("ガイダンスの取得に失敗: %v\n", err)
+	} else if guidance != nil {
+		response["guidance"] = guidance
+	}
+
+	c.JSON(http.StatusOK, response)
 }
 
 // GetLogs はユーザーの服用記録を取得するハンドラー
@@ -66,14 +138,55 @@ func (h *MedicationHandler) GetLogs(c *gin.Context) {
 		return
 	}
 
+	var query dto.MedicationLogListQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid query parameters"})
+		return
+	}
+
+	// 日付のみの指定はユーザーのタイムゾーンで解釈する
+	loc, err := h.medicationSvc.GetLocation(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get user"})
+		return
+	}
+
+	filter, err := buildLogFilter(query, loc)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// ページングの指定がない場合は従来どおり全件を配列で返す
+	if !isPagedLogQuery(query) {
+		logs, err := h.medicationRepo.FindLogs(userID, filter)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get medication logs"})
+			return
+		}
+		c.JSON(http.StatusOK, logs)
+		return
+	}
+
+	// 次のページの有無を判定するため1件多く取得する
+	limit := filter.Limit
+	filter.Limit = limit + 1
+
 	// 服用記録を取得
-	logs, err := h.medicationRepo.GetLogsByUserID(userID)
+	logs, err := h.medicationRepo.FindLogs(userID, filter)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get medication logs"})
 		return
 	}
 
-	c.JSON(http.StatusOK, logs)
+	response := dto.MedicationLogListResponse{Logs: logs}
+	if len(logs) > limit {
+		response.Logs = logs[:limit]
+		last := response.Logs[limit-1]
+		response.NextCursor = encodeLogCursor(repository.LogCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+
+	c.JSON(http.StatusOK, response)
 }
 
 // GetLogByID は特定のIDの服薬ログを取得するハンドラー
@@ -127,7 +240,15 @@ func (h *MedicationHandler) UpdateLog(c *gin.Context) {
 		return
 	}
 
-	err = h.medicationRepo.UpdateLog(userID, uint(logID), req.HasBleeding)
+	// 指定されなかった項目は既存の記録を維持する
+	medicationLog, err := h.medicationRepo.GetLogByID(userID, uint(logID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "medication log not found"})
+		return
+	}
+	applyLogEntry(medicationLog, req)
+
+	err = h.medicationRepo.UpdateLog(userID, uint(logID), *medicationLog)
 	if err != nil {
 		if err.Error() == "log not found or user not authorized" {
 			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
@@ -144,6 +265,101 @@ func (h *MedicationHandler) UpdateLog(c *gin.Context) {
 	})
 }
 
+// DeleteLog は指定されたIDの服薬ログを削除するハンドラー（論理削除）
+func (h *MedicationHandler) DeleteLog(c *gin.Context) {
+	// ユーザーIDを取得
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	// URLからIDパラメータを取得
+	logID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid log ID"})
+		return
+	}
+
+	// 在庫を戻すため削除前の服用記録を取得する
+	medicationLog, err := h.medicationRepo.GetLogByID(userID, uint(logID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "log not found or user not authorized"})
+		return
+	}
+
+	err = h.medicationRepo.DeleteLog(userID, uint(logID))
+	if err != nil {
+		if err.Error() == "log not found or user not authorized" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete medication log"})
+		return
+	}
+
+	// 服用した錠剤を在庫に戻す（飲み忘れの自動記録は在庫を減らしていないため対象外）
+	if !medicationLog.IsMissed {
+		if inventory := h.inventoryMedication(userID, medicationLog.MedicationID); inventory != nil {
+			if _, returnErr := h.medicationRepo.ReturnPill(userID, inventory.ID); returnErr != nil {
+				fmt.Printf("在庫の更新に失敗: %v\n", returnErr)
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, dto.BaseResponse{
+		Success: true,
+		Message: "medication log deleted successfully",
+	})
+}
+
+// RestoreLog は削除された服薬ログを復元するハンドラー
+func (h *MedicationHandler) RestoreLog(c *gin.Context) {
+	// ユーザーIDを取得
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	// URLからIDパラメータを取得
+	logID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid log ID"})
+		return
+	}
+
+	err = h.medicationRepo.RestoreLog(userID, uint(logID))
+	if err != nil {
+		if err.Error() == "deleted log not found or user not authorized" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to restore medication log"})
+		return
+	}
+
+	// 復元した服用記録の分の在庫を再び減らす
+	if medicationLog, getErr := h.medicationRepo.GetLogByID(userID, uint(logID)); getErr != nil {
+		fmt.Printf("復元した服用記録の取得に失敗: %v\n", getErr)
+	} else if !medicationLog.IsMissed {
+		if inventory := h.inventoryMedication(userID, medicationLog.MedicationID); inventory != nil {
+			takenAt := medicationLog.CreatedAt
+			if loc, locErr := h.medicationSvc.GetLocation(userID); locErr == nil {
+				takenAt = takenAt.In(loc)
+			}
+			if _, consumeErr := h.medicationRepo.ConsumePill(userID, inventory.ID, takenAt); consumeErr != nil {
+				fmt.Printf("在庫の更新に失敗: %v\n", consumeErr)
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, dto.BaseResponse{
+		Success: true,
+		Message: "medication log restored successfully",
+	})
+}
+
 // GetMedicationStatus は現在の服薬ステータスを取得するハンドラー
 func (h *MedicationHandler) GetMedicationStatus(c *gin.Context) {
 	// ユーザーIDを取得
@@ -153,15 +369,523 @@ func (h *MedicationHandler) GetMedicationStatus(c *gin.Context) {
 		return
 	}
 
-	// サービスから服薬ステータスを取得
-	medicationService := service.NewMedicationService(h.medicationRepo)
-	status, err := medicationService.GetMedicationStatus(userID)
+	// サービスから服薬ステータスを取得（日時が差し替えられている場合はその時点のステータス）
+	now, ok := helper.GetNowFromContext(c)
+	if !ok {
+		now = h.medicationSvc.Now()
+	}
+	status, err := h.medicationSvc.GetMedicationStatusAt(userID, now)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get medication status"})
 		return
 	}
 
+	// 体調の記録の傾向を合わせて返す（取得できなくてもステータスは返す）
+	trends, err := h.healthSvc.GetTrendsAt(userID, now)
+	if err != nil {
+		fmt.Printf("体調の傾向の取得に失敗: %v\n", err)
+	} else {
+		status.HealthTrends = trends
+	}
+
 	c.JSON(http.StatusOK, status)
 }
 
+// GetGuidance は現在の服用状況に対する対処ガイダンスを取得するハンドラー
+func (h *MedicationHandler) GetGuidance(c *gin.Context) {
+	// ユーザーIDを取得
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	// クエリパラメータから薬IDを取得（省略時は薬の指定がない記録が対象）
+	var medicationID *uint
+	if medicationIDStr := c.Query("medicationId"); medicationIDStr != "" {
+		parsed, parseErr := strconv.ParseUint(medicationIDStr, 10, 32)
+		if parseErr != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid medication ID"})
+			return
+		}
+		id := uint(parsed)
+		medicationID = &id
+	}
+
+	guidance, err := h.medicationSvc.GetCurrentGuidance(userID, medicationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get guidance"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"guidance": guidance})
+}
+
+// GetMedicationCalendar は指定月の日ごとの服薬状態を取得するハンドラー
+func (h *MedicationHandler) GetMedicationCalendar(c *gin.Context) {
+	// ユーザーIDを取得
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	// 対象月を取得（省略時は今月）
+	var month time.Time
+	if monthStr := c.Query("month"); monthStr != "" {
+		month, err = time.Parse("2006-01", monthStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid month format, expected YYYY-MM"})
+			return
+		}
+	}
+
+	calendar, err := h.medicationSvc.GetMedicationCalendar(userID, month)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get medication calendar"})
+		return
+	}
+
+	c.JSON(http.StatusOK, calendar)
+}
+
+// GetCycleHistory は過去の服用期間と休薬期間の履歴を取得するハンドラー
+func (h *MedicationHandler) GetCycleHistory(c *gin.Context) {
+	// ユーザーIDを取得
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	cycles, err := h.medicationSvc.GetCycleHistory(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get cycle history"})
+		return
+	}
+
+	c.JSON(http.StatusOK, cycles)
+}
+
+// GetMedicationStats は服用率や周期の統計を取得するハンドラー
+func (h *MedicationHandler) GetMedicationStats(c *gin.Context) {
+	// ユーザーIDを取得
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	// 集計期間を取得（省略時は30日）
+	period := c.DefaultQuery("period", dto.StatsPeriod30Days)
+	if !service.IsValidStatsPeriod(period) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid period, expected 30d, 90d or 1y"})
+		return
+	}
+
+	stats, err := h.medicationSvc.GetMedicationStats(userID, period)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get medication stats"})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// GetMedications はユーザーが登録した薬の一覧を取得するハンドラー
+func (h *MedicationHandler) GetMedications(c *gin.Context) {
+	// ユーザーIDを取得
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	medications, err := h.medicationRepo.GetMedicationsByUserID(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get medications"})
+		return
+	}
+
+	c.JSON(http.StatusOK, medications)
+}
+
+// GetMedicationByID は特定のIDの薬を取得するハンドラー
+func (h *MedicationHandler) GetMedicationByID(c *gin.Context) {
+	// ユーザーIDを取得
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	// URLからIDパラメータを取得
+	medicationID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid medication ID"})
+		return
+	}
+
+	medication, err := h.medicationRepo.GetMedicationByID(userID, uint(medicationID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "medication not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, medication)
+}
+
+// RegisterMedication は薬を登録するハンドラー
+func (h *MedicationHandler) RegisterMedication(c *gin.Context) {
+	// ユーザーIDを取得
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	// リクエストボディを構造体にバインド
+	var req dto.MedicationRequest
+	if bindErr := c.ShouldBindJSON(&req); bindErr != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	// 服用予定時刻はHH:MM形式である必要がある
+	if _, parseErr := time.Parse("15:04", req.ScheduledTime); req.ScheduledTime != "" && parseErr != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "scheduledTime must be in HH:MM format"})
+		return
+	}
+
+	medication := model.Medication{UserID: userID}
+	applyMedicationRequest(&medication, &req)
+
+	if err := h.medicationRepo.CreateMedication(&medication); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to register medication"})
+		return
+	}
+
+	c.JSON(http.StatusOK, medication)
+}
+
+// UpdateMedication は指定されたIDの薬を更新するハンドラー
+func (h *MedicationHandler) UpdateMedication(c *gin.Context) {
+	// ユーザーIDを取得
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
 
+	// URLからIDパラメータを取得
+	medicationID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid medication ID"})
+		return
+	}
+
+	// リクエストボディを構造体にバインド
+	var req dto.MedicationRequest
+	if bindErr := c.ShouldBindJSON(&req); bindErr != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	// 服用予定時刻はHH:MM形式である必要がある
+	if _, parseErr := time.Parse("15:04", req.ScheduledTime); req.ScheduledTime != "" && parseErr != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "scheduledTime must be in HH:MM format"})
+		return
+	}
+
+	medication, err := h.medicationRepo.GetMedicationByID(userID, uint(medicationID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "medication not found"})
+		return
+	}
+
+	applyMedicationRequest(medication, &req)
+
+	if err := h.medicationRepo.UpdateMedication(medication); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update medication"})
+		return
+	}
+
+	c.JSON(http.StatusOK, medication)
+}
+
+// PurchasePacks は購入したシートを在庫に追加するハンドラー
+func (h *MedicationHandler) PurchasePacks(c *gin.Context) {
+	// ユーザーIDを取得
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	// URLからIDパラメータを取得
+	medicationID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid medication ID"})
+		return
+	}
+
+	// リクエストボディを構造体にバインド
+	var req dto.PackPurchaseRequest
+	if bindErr := c.ShouldBindJSON(&req); bindErr != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	medication, err := h.medicationRepo.GetMedicationByID(userID, uint(medicationID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "medication not found"})
+		return
+	}
+
+	// 1シートの錠数が分からないと在庫を管理できない
+	pillsPerPack := 0
+	if req.PillsPerPack != nil {
+		pillsPerPack = *req.PillsPerPack
+	}
+	if pillsPerPack == 0 && !medication.TracksInventory() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "pillsPerPack is required for medications without inventory"})
+		return
+	}
+
+	if err := h.medicationRepo.AddPacks(userID, medication.ID, req.Packs, pillsPerPack); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to add packs"})
+		return
+	}
+
+	medication, err = h.medicationRepo.GetMedicationByID(userID, medication.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get medication"})
+		return
+	}
+
+	c.JSON(http.StatusOK, medication)
+}
+
+// DeleteMedication は指定されたIDの薬を削除するハンドラー
+func (h *MedicationHandler) DeleteMedication(c *gin.Context) {
+	// ユーザーIDを取得
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	// URLからIDパラメータを取得
+	medicationID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid medication ID"})
+		return
+	}
+
+	err = h.medicationRepo.DeleteMedication(userID, uint(medicationID))
+	if err != nil {
+		if err.Error() == "medication not found or user not authorized" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete medication"})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.BaseResponse{
+		Success: true,
+		Message: "medication deleted successfully",
+	})
+}
+
+// applyMedicationRequest はリクエストの内容を薬に反映する
+func applyMedicationRequest(medication *model.Medication, req *dto.MedicationRequest) {
+	medication.Name = req.Name
+	medication.Dosage = req.Dosage
+	medication.Form = req.Form
+	medication.Schedule = req.Schedule
+	if medication.Schedule == "" {
+		medication.Schedule = model.MedicationScheduleRegimen
+	}
+	medication.Color = req.Color
+	medication.Icon = req.Icon
+	medication.IsActive = req.IsActive == nil || *req.IsActive
+	medication.ScheduledTime = req.ScheduledTime
+	medication.WindowMinutes = defaultIntakeWindowMinutes
+	if req.WindowMinutes != nil {
+		medication.WindowMinutes = *req.WindowMinutes
+	}
+	medication.LateLimitMinutes = req.LateLimitMinutes
+
+	if req.PillsPerPack != nil {
+		medication.PillsPerPack = *req.PillsPerPack
+	}
+	if req.PacksOnHand != nil {
+		medication.PacksOnHand = *req.PacksOnHand
+	}
+	if req.CurrentPackStartDate != nil {
+		medication.CurrentPackStartDate = req.CurrentPackStartDate
+	}
+	if req.CurrentPackPillsLeft != nil {
+		medication.CurrentPackPillsLeft = *req.CurrentPackPillsLeft
+	}
+	if req.RefillThresholdPills != nil {
+		medication.RefillThresholdPills = *req.RefillThresholdPills
+	} else if medication.ID == 0 {
+		medication.RefillThresholdPills = model.DefaultRefillThresholdPills
+	}
+}
+
+// inventoryMedication は服用記録の在庫を更新する薬を返す（在庫を管理していない場合はnil）
+// 薬の指定がない記録は、服用中の薬が1つだけの場合にその薬の在庫を更新する
+func (h *MedicationHandler) inventoryMedication(userID string, medicationID *uint) *model.Medication {
+	var medication *model.Medication
+	if medicationID != nil {
+		found, err := h.medicationRepo.GetMedicationByID(userID, *medicationID)
+		if err != nil {
+			return nil
+		}
+		medication = found
+	} else {
+		medications, err := h.medicationRepo.GetMedicationsByUserID(userID)
+		if err != nil {
+			return nil
+		}
+		medication = service.DefaultMedication(medications)
+	}
+
+	if medication == nil || !medication.TracksInventory() {
+		return nil
+	}
+	return medication
+}
+
+// applyLogEntry はリクエストの出血の程度・痛み・症状・メモ・タグを服用記録に反映する
+// 出血の程度が指定されていない場合、applyHasBleedingがtrueであれば出血の有無から程度を設定する
+func applyLogEntry(medicationLog *model.MedicationLog, req dto.MedicationLogRequest) {
+	// 出血の有無は指定され、かつ現在の記録と異なる場合のみ反映する（記録済みの出血の程度を維持する）
+	switch {
+	case req.BleedingLevel != nil:
+		medicationLog.SetBleedingLevel(*req.BleedingLevel)
+	case req.HasBleeding != nil && *req.HasBleeding != medicationLog.HasBleeding:
+		medicationLog.SetHasBleeding(*req.HasBleeding)
+	}
+
+	if req.PainScore != nil {
+		medicationLog.PainScore = req.PainScore
+	}
+	if req.Symptoms != nil {
+		medicationLog.Symptoms = model.StringList(req.Symptoms)
+	}
+	if req.Note != nil {
+		medicationLog.Note = strings.TrimSpace(*req.Note)
+	}
+	if req.Tags != nil {
+		medicationLog.Tags = normalizeTags(req.Tags)
+	}
+}
+
+// normalizeTags は前後の空白を除去し、空のタグと重複を取り除く
+func normalizeTags(tags []string) model.StringList {
+	normalized := model.StringList{}
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || normalized.Contains(tag) {
+			continue
+		}
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+// buildLogFilter は一覧取得のクエリパラメータを取得条件に変換する
+func buildLogFilter(query dto.MedicationLogListQuery, loc *time.Location) (repository.LogFilter, error) {
+	filter := repository.LogFilter{
+		Limit: query.Limit,
+		Order: query.Order,
+		Query: strings.TrimSpace(query.Q),
+		Tag:   strings.TrimSpace(query.Tag),
+	}
+	// ページングする場合のみ件数を制限する（0は無制限）
+	if filter.Limit == 0 && isPagedLogQuery(query) {
+		filter.Limit = defaultLogListLimit
+	}
+	if filter.Order == "" {
+		filter.Order = repository.LogOrderDesc
+	}
+
+	if query.From != "" {
+		from, _, err := parseLogQueryTime(query.From, loc)
+		if err != nil {
+			return filter, fmt.Errorf("invalid from: %s", query.From)
+		}
+		filter.From = &from
+	}
+	if query.To != "" {
+		to, dateOnly, err := parseLogQueryTime(query.To, loc)
+		if err != nil {
+			return filter, fmt.Errorf("invalid to: %s", query.To)
+		}
+		// 日付のみの場合はその日の終わりまでを含める
+		if dateOnly {
+			to = to.AddDate(0, 0, 1)
+		}
+		filter.To = &to
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return filter, fmt.Errorf("from must be before to")
+	}
+
+	if query.Cursor != "" {
+		cursor, err := decodeLogCursor(query.Cursor)
+		if err != nil {
+			return filter, fmt.Errorf("invalid cursor")
+		}
+		filter.Cursor = &cursor
+	}
+
+	return filter, nil
+}
+
+// isPagedLogQuery はページングの指定（limitまたはcursor）があるかどうかを判定する
+func isPagedLogQuery(query dto.MedicationLogListQuery) bool {
+	return query.Limit > 0 || query.Cursor != ""
+}
+
+// parseLogQueryTime はYYYY-MM-DDまたはRFC3339の日時を解釈する（日付のみかどうかも返す）
+func parseLogQueryTime(value string, loc *time.Location) (time.Time, bool, error) {
+	if t, err := time.ParseInLocation("2006-01-02", value, loc); err == nil {
+		return t, true, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	return t, false, err
+}
+
+// encodeLogCursor はページングの位置をクライアントに返す文字列に変換する
+func encodeLogCursor(cursor repository.LogCursor) string {
+	raw := fmt.Sprintf("%d:%d", cursor.CreatedAt.UnixNano(), cursor.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeLogCursor はencodeLogCursorで生成した文字列をページングの位置に戻す
+func decodeLogCursor(value string) (repository.LogCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return repository.LogCursor{}, err
+	}
+
+	parts := strings.SplitN(string(raw), ":", 2)
+	if len(parts) != 2 {
+		return repository.LogCursor{}, fmt.Errorf("malformed cursor")
+	}
+	nanos, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return repository.LogCursor{}, err
+	}
+	id, err := strconv.ParseUint(parts[1], 10, 32)
+	if err != nil {
+		return repository.LogCursor{}, err
+	}
+
+	return repository.LogCursor{CreatedAt: time.Unix(0, nanos), ID: uint(id)}, nil
+}
@@ -0,0 +1,213 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"okusuri-backend/internal/dto"
+	"okusuri-backend/internal/model"
+	"okusuri-backend/internal/repository"
+	"okusuri-backend/internal/testutil"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 服用記録一覧の取得条件のテスト
+func TestBuildLogFilter(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+
+	t.Run("ページングの指定がない場合は件数を制限せず降順になる", func(t *testing.T) {
+		filter, err := buildLogFilter(dto.MedicationLogListQuery{}, tokyo)
+		require.NoError(t, err)
+		assert.Equal(t, 0, filter.Limit)
+		assert.Equal(t, repository.LogOrderDesc, filter.Order)
+		assert.Nil(t, filter.From)
+		assert.Nil(t, filter.To)
+	})
+
+	t.Run("カーソルのみの指定はデフォルトの件数になる", func(t *testing.T) {
+		cursor := encodeLogCursor(repository.LogCursor{CreatedAt: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), ID: 1})
+		filter, err := buildLogFilter(dto.MedicationLogListQuery{Cursor: cursor}, tokyo)
+		require.NoError(t, err)
+		assert.Equal(t, defaultLogListLimit, filter.Limit)
+	})
+
+	t.Run("日付のみの指定はユーザーのタイムゾーンで終了日を含む", func(t *testing.T) {
+		filter, err := buildLogFilter(dto.MedicationLogListQuery{From: "2025-03-01", To: "2025-03-31"}, tokyo)
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2025, 3, 1, 0, 0, 0, 0, tokyo), *filter.From)
+		assert.Equal(t, time.Date(2025, 4, 1, 0, 0, 0, 0, tokyo), *filter.To)
+	})
+
+	t.Run("開始日が終了日より後の場合はエラー", func(t *testing.T) {
+		_, err := buildLogFilter(dto.MedicationLogListQuery{From: "2025-04-01", To: "2025-03-01"}, tokyo)
+		assert.Error(t, err)
+	})
+
+	t.Run("不正なカーソルはエラー", func(t *testing.T) {
+		_, err := buildLogFilter(dto.MedicationLogListQuery{Cursor: "not-a-cursor"}, tokyo)
+		assert.Error(t, err)
+	})
+}
+
+// ページングカーソルのテスト
+func TestLogCursor(t *testing.T) {
+	t.Run("エンコードしたカーソルを元に戻せる", func(t *testing.T) {
+		cursor := repository.LogCursor{
+			CreatedAt: time.Date(2025, 3, 1, 8, 30, 15, 123456000, time.UTC),
+			ID:        42,
+		}
+
+		decoded, err := decodeLogCursor(encodeLogCursor(cursor))
+		require.NoError(t, err)
+		assert.True(t, cursor.CreatedAt.Equal(decoded.CreatedAt))
+		assert.Equal(t, cursor.ID, decoded.ID)
+	})
+}
+
+// 出血の程度・痛み・症状の反映のテスト
+func TestApplyLogEntry(t *testing.T) {
+	t.Run("出血の有無のみの場合は少量の出血として記録される", func(t *testing.T) {
+		var log model.MedicationLog
+		hasBleeding := true
+		applyLogEntry(&log, dto.MedicationLogRequest{HasBleeding: &hasBleeding})
+
+		assert.True(t, log.HasBleeding)
+		assert.Equal(t, model.BleedingLevelLight, log.BleedingLevel)
+	})
+
+	t.Run("出血の程度が指定された場合は出血の有無を連動させる", func(t *testing.T) {
+		var log model.MedicationLog
+		level := model.BleedingLevelSpotting
+		pain := 4
+		hasBleeding := false
+		applyLogEntry(&log, dto.MedicationLogRequest{
+			HasBleeding:   &hasBleeding,
+			BleedingLevel: &level,
+			PainScore:     &pain,
+			Symptoms:      []string{model.SymptomHeadache},
+		})
+
+		assert.True(t, log.HasBleeding)
+		assert.Equal(t, model.BleedingLevelSpotting, log.BleedingLevel)
+		assert.Equal(t, 4, *log.PainScore)
+		assert.Equal(t, model.StringList{model.SymptomHeadache}, log.Symptoms)
+	})
+
+	t.Run("更新時に指定されなかった項目は維持される", func(t *testing.T) {
+		pain := 6
+		log := model.MedicationLog{PainScore: &pain, Symptoms: model.StringList{model.SymptomNausea}}
+		log.SetBleedingLevel(model.BleedingLevelHeavy)
+
+		hasBleeding := true
+		applyLogEntry(&log, dto.MedicationLogRequest{HasBleeding: &hasBleeding})
+
+		assert.Equal(t, model.BleedingLevelHeavy, log.BleedingLevel)
+		assert.Equal(t, 6, *log.PainScore)
+		assert.Equal(t, model.StringList{model.SymptomNausea}, log.Symptoms)
+	})
+
+	t.Run("出血の有無を省略して痛みのみ更新しても出血の程度は維持される", func(t *testing.T) {
+		log := model.MedicationLog{}
+		log.SetBleedingLevel(model.BleedingLevelMedium)
+		pain := 3
+
+		applyLogEntry(&log, dto.MedicationLogRequest{PainScore: &pain})
+
+		assert.True(t, log.HasBleeding)
+		assert.Equal(t, model.BleedingLevelMedium, log.BleedingLevel)
+		assert.Equal(t, 3, *log.PainScore)
+	})
+
+	t.Run("出血なしを指定すると出血の程度がなしになる", func(t *testing.T) {
+		log := model.MedicationLog{}
+		log.SetBleedingLevel(model.BleedingLevelLight)
+		hasBleeding := false
+
+		applyLogEntry(&log, dto.MedicationLogRequest{HasBleeding: &hasBleeding})
+
+		assert.False(t, log.HasBleeding)
+		assert.Equal(t, model.BleedingLevelNone, log.BleedingLevel)
+	})
+}
+
+// タグの正規化のテスト
+func TestNormalizeTags(t *testing.T) {
+	t.Run("空白の除去と重複・空のタグの除外が行われる", func(t *testing.T) {
+		tags := normalizeTags([]string{" 食後 ", "嘔吐", "", "食後", "  "})
+		assert.Equal(t, model.StringList{"食後", "嘔吐"}, tags)
+	})
+
+	t.Run("空の一覧を指定するとタグが削除される", func(t *testing.T) {
+		log := model.MedicationLog{Tags: model.StringList{"食後"}}
+		applyLogEntry(&log, dto.MedicationLogRequest{Tags: []string{}})
+		assert.Empty(t, log.Tags)
+	})
+}
+
+// newLogRouter は認証済みユーザーとして服用記録の削除・復元を呼び出すテスト用のルーターを作成する
+func newLogRouter(handler *MedicationHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user", &model.User{ID: "test-user"})
+	})
+	router.DELETE("/api/medication-log/:id", handler.DeleteLog)
+	router.POST("/api/medication-log/:id/restore", handler.RestoreLog)
+	return router
+}
+
+// 服用記録の削除のテスト
+func TestMedicationHandler_DeleteLog(t *testing.T) {
+	t.Run("存在しない服用記録は404", func(t *testing.T) {
+		testutil.NewFakeDB(t)
+		router := newLogRouter(NewMedicationHandler(repository.NewMedicationRepository(), nil, nil))
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/api/medication-log/1", nil))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.JSONEq(t, `{"error":"log not found or user not authorized"}`, w.Body.String())
+	})
+
+	t.Run("不正なIDは400", func(t *testing.T) {
+		testutil.NewFakeDB(t)
+		router := newLogRouter(NewMedicationHandler(repository.NewMedicationRepository(), nil, nil))
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/api/medication-log/abc", nil))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+// 服用記録の復元のテスト
+func TestMedicationHandler_RestoreLog(t *testing.T) {
+	t.Run("存在しない服用記録は404", func(t *testing.T) {
+		testutil.NewFakeDB(t)
+		router := newLogRouter(NewMedicationHandler(repository.NewMedicationRepository(), nil, nil))
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/medication-log/1/restore", nil))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.JSONEq(t, `{"error":"deleted log not found or user not authorized"}`, w.Body.String())
+	})
+
+	t.Run("削除されていない服用記録は復元できず404", func(t *testing.T) {
+		// 削除されていない記録は削除済みの条件に一致しないため、更新される行はない
+		db := testutil.NewFakeDB(t)
+		db.OnExec(`UPDATE "medication_logs" SET "deleted_at"`, 0)
+		router := newLogRouter(NewMedicationHandler(repository.NewMedicationRepository(), nil, nil))
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/medication-log/1/restore", nil))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.True(t, db.Executed("deleted_at IS NOT NULL"))
+	})
+}
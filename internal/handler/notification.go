@@ -1,7 +1,6 @@
 package handler
 
 import (
-	"fmt"
 	"net/http"
 	"okusuri-backend/internal/dto"
 	"okusuri-backend/internal/model"
@@ -15,25 +14,16 @@ import (
 
 type NotificationHandler struct {
 	notificationRepo *repository.NotificationRepository
-	userRepo         *repository.UserRepository
-	notificationSvc  *service.NotificationService
-	medicationRepo   *repository.MedicationRepository
-	medicationSvc    *service.MedicationService
+	reminderSvc      *service.ReminderService
 }
 
 func NewNotificationHandler(
 	notificationRepo *repository.NotificationRepository,
-	userRepo *repository.UserRepository,
-	notificationSvc *service.NotificationService,
-	medicationRepo *repository.MedicationRepository,
-	medicationSvc *service.MedicationService,
+	reminderSvc *service.ReminderService,
 ) *NotificationHandler {
 	return &NotificationHandler{
 		notificationRepo: notificationRepo,
-		userRepo:         userRepo,
-		notificationSvc:  notificationSvc,
-		medicationRepo:   medicationRepo,
-		medicationSvc:    medicationSvc,
+		reminderSvc:      reminderSvc,
 	}
 }
 
@@ -72,16 +62,19 @@ func (h *NotificationHandler) RegisterSetting(c *gin.Context) {
 		return
 	}
 
-	// 通知設定をモデルに変換
-	setting := model.NotificationSetting{
-		UserID:       userID,
-		IsEnabled:    req.IsEnabled,
-		Platform:     req.Platform,
-		Subscription: req.Subscription,
+	// IANAタイムゾーン名として解釈できるか検証
+	if req.Timezone != nil && *req.Timezone != "" {
+		if _, loadErr := time.LoadLocation(*req.Timezone); loadErr != nil || *req.Timezone == "Local" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid timezone"})
+			return
+		}
 	}
 
-	// リポジトリに登録処理を依頼
-	if err := h.notificationRepo.RegisterSetting(&setting); err != nil {
+	// 通知設定をモデルに変換
+	setting, columns := buildNotificationSetting(userID, req)
+
+	// リポジトリに登録処理を依頼（省略された項目は既存の設定を維持する）
+	if err := h.notificationRepo.RegisterSetting(&setting, columns...); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to register notification setting"})
 		return
 	}
@@ -89,153 +82,80 @@ func (h *NotificationHandler) RegisterSetting(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "notification setting registered successfully"})
 }
 
-// SendNotification は通知を送信するハンドラー
-func (h *NotificationHandler) SendNotification(c *gin.Context) {
-	requestTime := time.Now()
-	h.logRequestStart(c, requestTime)
-
-	users, settings, err := h.fetchUsersAndSettings(c)
-	if err != nil {
+// PerformAction は通知のアクションボタンから服用記録の登録やスヌーズを行うハンドラー
+// ロック画面から操作できるよう、セッションではなく通知に含まれる署名付きのアクショントークンで認証する
+func (h *NotificationHandler) PerformAction(c *gin.Context) {
+	var req dto.NotificationActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
 		return
 	}
 
-	settingsMap := h.buildSettingsMap(settings)
-	sentCount := h.processNotifications(users, settingsMap)
-
-	h.logAndRespond(c, requestTime, sentCount)
-}
-
-// logRequestStart はリクエスト開始時のログを出力する
-func (h *NotificationHandler) logRequestStart(c *gin.Context, requestTime time.Time) {
-	fmt.Printf("\n========== 通知送信処理開始 [%s] ==========\n", requestTime.Format("2006-01-02 15:04:05"))
-	fmt.Printf("リクエストパス: %s\n", c.Request.URL.Path)
-	fmt.Printf("リクエスト元IP: %s\n", c.ClientIP())
-	fmt.Printf("リクエストID: %s\n", c.Writer.Header().Get("Request-ID"))
-}
-
-// fetchUsersAndSettings はユーザーと通知設定を取得する
-func (h *NotificationHandler) fetchUsersAndSettings(c *gin.Context) ([]model.User, []model.NotificationSetting, error) {
-	users, userErr := h.userRepo.GetAllUsers()
-	if userErr != nil {
-		fmt.Printf("エラー: ユーザー取得失敗: %v\n", userErr)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get users"})
-		return nil, nil, userErr
-	}
-	fmt.Printf("取得したユーザー数: %d\n", len(users))
-
-	settings, settingsErr := h.notificationRepo.GetAllSettings()
-	if settingsErr != nil {
-		fmt.Printf("エラー: 通知設定取得失敗: %v\n", settingsErr)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get notification settings"})
-		return nil, nil, settingsErr
-	}
-	fmt.Printf("取得した通知設定数: %d\n", len(settings))
-
-	return users, settings, nil
-}
-
-// buildSettingsMap は通知設定をユーザーIDでマップ化する
-func (h *NotificationHandler) buildSettingsMap(
-	settings []model.NotificationSetting,
-) map[string]model.NotificationSetting {
-	settingsMap := make(map[string]model.NotificationSetting)
-	for _, setting := range settings {
-		existingSetting, exists := settingsMap[setting.UserID]
-		if !exists || setting.UpdatedAt.After(existingSetting.UpdatedAt) {
-			settingsMap[setting.UserID] = setting
-		}
-	}
-	fmt.Printf("通知対象ユーザー数: %d\n", len(settingsMap))
-	return settingsMap
-}
-
-// processNotifications は各ユーザーに通知を送信する
-func (h *NotificationHandler) processNotifications(
-	users []model.User, settingsMap map[string]model.NotificationSetting,
-) int {
-	sentSubs := make(map[string]bool)
-	fmt.Println("----- 通知送信処理開始 -----")
-
-	for _, user := range users {
-		if h.sendUserNotification(user, settingsMap, sentSubs) {
-			fmt.Printf("ユーザーID: %s への通知送信成功\n", user.ID)
+	if err := h.reminderSvc.PerformAction(req.Token, req.Action); err != nil {
+		switch err.Error() {
+		case "invalid or expired action token":
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		case "action token already used":
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to perform notification action"})
 		}
+		return
 	}
 
-	fmt.Printf("----- 通知送信処理完了: 合計%d件送信 -----\n", len(sentSubs))
-	return len(sentSubs)
+	c.JSON(http.StatusOK, dto.BaseResponse{
+		Success: true,
+		Message: "notification action performed successfully",
+	})
 }
 
-// sendUserNotification は個別ユーザーに通知を送信する
-func (h *NotificationHandler) sendUserNotification(
-	user model.User, settingsMap map[string]model.NotificationSetting, sentSubs map[string]bool,
-) bool {
-	setting, ok := settingsMap[user.ID]
-	if !ok || !setting.IsEnabled {
-		return false
-	}
+// buildNotificationSetting はリクエストを通知設定に変換し、既存の設定を更新する列（リクエストで指定された項目）を返す
+// 省略された項目は新規登録の場合はデフォルト値とし、既存の設定を更新する場合は変更しない
+func buildNotificationSetting(userID string, req dto.RegisterNotificationSettingRequest) (model.NotificationSetting, []string) {
+	setting := model.NotificationSetting{
+		UserID:           userID,
+		IsEnabled:        req.IsEnabled,
+		Platform:         req.Platform,
+		Subscription:     req.Subscription,
+		ReminderTimes:    uniqueStrings(req.ReminderTimes),
+		ReminderWeekdays: uniqueStrings(req.ReminderWeekdays),
 
-	if _, alreadySent := sentSubs[setting.Subscription]; alreadySent && setting.Subscription != "" {
-		return false
+		FollowUpIntervalMinutes: model.DefaultFollowUpIntervalMinutes,
+		FollowUpMaxCount:        model.DefaultFollowUpMaxCount,
 	}
 
-	message := h.getNotificationMessage(user.ID)
-	medicationStatus, statusErr := h.medicationSvc.GetMedicationStatus(user.ID)
-	if statusErr == nil {
-		message = h.generateStatusBasedMessage(medicationStatus)
+	var columns []string
+	if req.ReminderTimes != nil {
+		columns = append(columns, "reminder_times")
 	}
-
-	consecutiveDays := 0
-	if statusErr == nil {
-		consecutiveDays = medicationStatus.CurrentStreak
+	if req.ReminderWeekdays != nil {
+		columns = append(columns, "reminder_weekdays")
 	}
-
-	sendErr := h.notificationSvc.SendNotificationWithDays(user, setting, message, consecutiveDays)
-	if sendErr != nil {
-		fmt.Printf("エラー: 通知送信失敗: %v\n", sendErr)
-		return false
+	if req.Timezone != nil {
+		setting.Timezone = *req.Timezone
+		columns = append(columns, "timezone")
 	}
-
-	if setting.Subscription != "" {
-		sentSubs[setting.Subscription] = true
+	if req.FollowUpIntervalMinutes != nil {
+		setting.FollowUpIntervalMinutes = *req.FollowUpIntervalMinutes
+		columns = append(columns, "follow_up_interval_minutes")
 	}
-	return true
-}
-
-// getNotificationMessage はデフォルトの通知メッセージを取得する
-func (h *NotificationHandler) getNotificationMessage(userID string) string {
-	return "お薬の時間です。忘れずに服用してください。"
-}
-
-// logAndRespond は処理結果をログ出力してレスポンスを返す
-func (h *NotificationHandler) logAndRespond(c *gin.Context, requestTime time.Time, sentCount int) {
-	processingTime := time.Since(requestTime)
-	fmt.Printf("処理時間: %v\n", processingTime)
-
-	c.JSON(http.StatusOK, gin.H{
-		"message":         "notification sent successfully",
-		"sent_count":      sentCount,
-		"process_time_ms": processingTime.Milliseconds(),
-	})
-	fmt.Printf("========== 通知送信処理終了 [%s] ==========\n\n",
-		time.Now().Format("2006-01-02 15:04:05"))
+	if req.FollowUpMaxCount != nil {
+		setting.FollowUpMaxCount = *req.FollowUpMaxCount
+		columns = append(columns, "follow_up_max_count")
+	}
+	return setting, columns
 }
 
-// generateStatusBasedMessage はユーザーの薬のステータスに応じた通知メッセージを生成する
-func (h *NotificationHandler) generateStatusBasedMessage(status *dto.MedicationStatusResponse) string {
-	if status.IsRestPeriod {
-		// 休薬期間中のメッセージ
-		if status.RestDaysLeft > 0 {
-			return fmt.Sprintf("現在休薬期間中です。あと%d日で服薬を再開してください。", status.RestDaysLeft)
-		} else {
-			return "休薬期間が終了しました。本日から服薬を再開してください。"
-		}
-	} else {
-		// 通常の服薬期間のメッセージ
-		if status.CurrentStreak > 0 {
-			return fmt.Sprintf("お薬の時間です。忘れずに服用してください。（連続%d日目）", status.CurrentStreak)
-		} else {
-			return "お薬の時間です。忘れずに服用してください。"
+// uniqueStrings は重複を除いた一覧を返す
+func uniqueStrings(values []string) model.StringList {
+	result := model.StringList{}
+	seen := make(map[string]bool)
+	for _, value := range values {
+		if seen[value] {
+			continue
 		}
+		seen[value] = true
+		result = append(result, value)
 	}
+	return result
 }
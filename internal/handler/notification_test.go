@@ -1,8 +1,19 @@
 package handler
 
 import (
+	"database/sql/driver"
+	"net/http"
+	"net/http/httptest"
+	"okusuri-backend/internal/dto"
+	"okusuri-backend/internal/model"
+	"okusuri-backend/internal/repository"
+	"okusuri-backend/internal/service"
+	"okusuri-backend/internal/testutil"
+	"okusuri-backend/pkg/clock"
+	"strings"
 	"testing"
 
+	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -12,7 +23,111 @@ func TestNotificationHandler_New(t *testing.T) {
 		// NotificationHandlerの作成をテスト
 		// 実際の依存関係は使わずに、nilで作成してもパニックしないことを確認
 		assert.NotPanics(t, func() {
-			NewNotificationHandler(nil, nil, nil, nil, nil)
+			NewNotificationHandler(nil, nil)
 		})
 	})
 }
+
+// 通知設定の登録で省略された項目のテスト
+func TestBuildNotificationSetting(t *testing.T) {
+	t.Run("省略された項目は更新する列に含まれずデフォルト値になる", func(t *testing.T) {
+		setting, columns := buildNotificationSetting("test-user", dto.RegisterNotificationSettingRequest{
+			Subscription: "subscription", IsEnabled: true, Platform: "web",
+		})
+
+		assert.Empty(t, columns)
+		assert.Equal(t, model.DefaultFollowUpIntervalMinutes, setting.FollowUpIntervalMinutes)
+		assert.Equal(t, model.DefaultFollowUpMaxCount, setting.FollowUpMaxCount)
+		assert.Empty(t, setting.ReminderTimes)
+	})
+
+	t.Run("指定された項目のみ更新する列に含まれる", func(t *testing.T) {
+		timezone := "Asia/Tokyo"
+		maxCount := 0
+		setting, columns := buildNotificationSetting("test-user", dto.RegisterNotificationSettingRequest{
+			Subscription: "subscription", IsEnabled: true, Platform: "web",
+			ReminderWeekdays: []string{},
+			Timezone:         &timezone,
+			FollowUpMaxCount: &maxCount,
+		})
+
+		assert.Equal(t, []string{"reminder_weekdays", "timezone", "follow_up_max_count"}, columns)
+		assert.Equal(t, "Asia/Tokyo", setting.Timezone)
+		assert.Equal(t, 0, setting.FollowUpMaxCount)
+	})
+}
+
+// 通知設定の登録（既存の設定の更新）のテスト
+func TestNotificationHandler_RegisterSetting(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	t.Run("購読情報のみの更新では通知時刻や再通知の設定を上書きしない", func(t *testing.T) {
+		db := testutil.NewFakeDB(t)
+		router := gin.New()
+		router.Use(func(c *gin.Context) {
+			c.Set("user", &model.User{ID: "test-user"})
+		})
+		router.POST("/api/notification/setting",
+			NewNotificationHandler(repository.NewNotificationRepository(), nil).RegisterSetting)
+
+		body := `{"subscription":"new-subscription","isEnabled":true,"platform":"web"}`
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/notification/setting", strings.NewReader(body)))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.True(t, db.Executed(`"subscription"="excluded"."subscription"`))
+		for _, column := range []string{"reminder_times", "reminder_weekdays", "timezone", "follow_up_interval_minutes", "follow_up_max_count"} {
+			assert.False(t, db.Executed(`"`+column+`"="excluded"`), column)
+		}
+	})
+}
+
+// 通知のアクションの実行のテスト
+func TestNotificationHandler_PerformAction(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("NOTIFICATION_ACTION_SECRET", "test-secret")
+
+	notificationSvc := service.NewNotificationService(clock.New())
+	reminderSvc := service.NewReminderService(nil, repository.NewNotificationRepository(),
+		repository.NewMedicationRepository(), nil, nil, notificationSvc)
+	router := gin.New()
+	router.POST("/api/notification/action", NewNotificationHandler(nil, reminderSvc).PerformAction)
+
+	perform := func(token, action string) *httptest.ResponseRecorder {
+		body := `{"token":"` + token + `","action":"` + action + `"}`
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/notification/action", strings.NewReader(body)))
+		return w
+	}
+
+	t.Run("トークンは1度だけ使用でき、2度目は409", func(t *testing.T) {
+		db := testutil.NewFakeDB(t)
+		db.OnQuery(`INSERT INTO "notification_dispatches"`, []string{"id"}, []driver.Value{int64(1)})
+		token, err := notificationSvc.IssueActionToken("test-user", nil)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusOK, perform(token, "taken").Code)
+
+		// 他のリクエストで使用済みの記録が登録された後は登録できない
+		db.OnQuery(`INSERT INTO "notification_dispatches"`, []string{"id"})
+		w := perform(token, "taken")
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.JSONEq(t, `{"error":"action token already used"}`, w.Body.String())
+	})
+
+	t.Run("不正なトークンは401", func(t *testing.T) {
+		testutil.NewFakeDB(t)
+
+		w := perform("invalid-token", "snooze")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.JSONEq(t, `{"error":"invalid or expired action token"}`, w.Body.String())
+	})
+
+	t.Run("不正なアクションは400", func(t *testing.T) {
+		testutil.NewFakeDB(t)
+		token, err := notificationSvc.IssueActionToken("test-user", nil)
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusBadRequest, perform(token, "unknown").Code)
+	})
+}
@@ -0,0 +1,200 @@
+package handler
+
+import (
+	"net/http"
+	"okusuri-backend/internal/dto"
+	"okusuri-backend/internal/model"
+	"okusuri-backend/internal/repository"
+	"okusuri-backend/pkg/helper"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PrescriptionHandler struct {
+	prescriptionRepo *repository.PrescriptionRepository
+	medicationRepo   *repository.MedicationRepository
+}
+
+func NewPrescriptionHandler(
+	prescriptionRepo *repository.PrescriptionRepository,
+	medicationRepo *repository.MedicationRepository,
+) *PrescriptionHandler {
+	return &PrescriptionHandler{
+		prescriptionRepo: prescriptionRepo,
+		medicationRepo:   medicationRepo,
+	}
+}
+
+// GetPrescriptions は処方箋の一覧を取得するハンドラー
+func (h *PrescriptionHandler) GetPrescriptions(c *gin.Context) {
+	// ユーザーIDを取得
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	prescriptions, err := h.prescriptionRepo.GetByUserID(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get prescriptions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, prescriptions)
+}
+
+// RegisterPrescription は処方箋を登録するハンドラー
+func (h *PrescriptionHandler) RegisterPrescription(c *gin.Context) {
+	// ユーザーIDを取得
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	req, ok := h.bindPrescriptionRequest(c, userID)
+	if !ok {
+		return
+	}
+
+	prescription := model.Prescription{UserID: userID}
+	applyPrescriptionRequest(&prescription, req)
+
+	if err := h.prescriptionRepo.Create(&prescription); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to register prescription"})
+		return
+	}
+
+	c.JSON(http.StatusOK, prescription)
+}
+
+// GetPrescriptionByID は指定されたIDの処方箋を取得するハンドラー
+func (h *PrescriptionHandler) GetPrescriptionByID(c *gin.Context) {
+	// ユーザーIDを取得
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	// URLからIDパラメータを取得
+	prescriptionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid prescription ID"})
+		return
+	}
+
+	prescription, err := h.prescriptionRepo.GetByID(userID, uint(prescriptionID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "prescription not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, prescription)
+}
+
+// UpdatePrescription は指定されたIDの処方箋を更新するハンドラー
+func (h *PrescriptionHandler) UpdatePrescription(c *gin.Context) {
+	// ユーザーIDを取得
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	// URLからIDパラメータを取得
+	prescriptionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid prescription ID"})
+		return
+	}
+
+	req, ok := h.bindPrescriptionRequest(c, userID)
+	if !ok {
+		return
+	}
+
+	prescription, err := h.prescriptionRepo.GetByID(userID, uint(prescriptionID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "prescription not found"})
+		return
+	}
+
+	applyPrescriptionRequest(prescription, req)
+
+	if err := h.prescriptionRepo.Update(prescription); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update prescription"})
+		return
+	}
+
+	c.JSON(http.StatusOK, prescription)
+}
+
+// DeletePrescription は指定されたIDの処方箋を削除するハンドラー
+func (h *PrescriptionHandler) DeletePrescription(c *gin.Context) {
+	// ユーザーIDを取得
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	// URLからIDパラメータを取得
+	prescriptionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid prescription ID"})
+		return
+	}
+
+	err = h.prescriptionRepo.Delete(userID, uint(prescriptionID))
+	if err != nil {
+		if err.Error() == "prescription not found or user not authorized" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete prescription"})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.BaseResponse{
+		Success: true,
+		Message: "prescription deleted successfully",
+	})
+}
+
+// bindPrescriptionRequest はリクエストボディをバインドして検証する
+func (h *PrescriptionHandler) bindPrescriptionRequest(c *gin.Context, userID string) (*dto.PrescriptionRequest, bool) {
+	var req dto.PrescriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return nil, false
+	}
+
+	// 有効期限は発行日以降である必要がある
+	if req.ExpiresOn.Before(req.IssuedOn) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "expiresOn must be on or after issuedOn"})
+		return nil, false
+	}
+
+	// 薬が指定されている場合は、ユーザーが登録した薬か確認する
+	if req.MedicationID != nil {
+		if _, err := h.medicationRepo.GetMedicationByID(userID, *req.MedicationID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "medication not found"})
+			return nil, false
+		}
+	}
+
+	return &req, true
+}
+
+// applyPrescriptionRequest はリクエストの内容を処方箋に反映する
+func applyPrescriptionRequest(prescription *model.Prescription, req *dto.PrescriptionRequest) {
+	prescription.MedicationID = req.MedicationID
+	prescription.Prescriber = req.Prescriber
+	prescription.Clinic = req.Clinic
+	prescription.IssuedOn = req.IssuedOn
+	prescription.ExpiresOn = req.ExpiresOn
+	prescription.RefillsLeft = req.RefillsLeft
+	prescription.Note = req.Note
+}
@@ -0,0 +1,192 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"okusuri-backend/internal/dto"
+	"okusuri-backend/internal/model"
+	"okusuri-backend/internal/repository"
+	"okusuri-backend/internal/service"
+	"okusuri-backend/pkg/helper"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+type RegimenHandler struct {
+	regimenRepo   *repository.RegimenRepository
+	medicationSvc *service.MedicationService
+}
+
+func NewRegimenHandler(
+	regimenRepo *repository.RegimenRepository,
+	medicationSvc *service.MedicationService,
+) *RegimenHandler {
+	return &RegimenHandler{
+		regimenRepo:   regimenRepo,
+		medicationSvc: medicationSvc,
+	}
+}
+
+// GetRegimen はユーザーのレジメンを取得するハンドラー（未登録の場合はデフォルト値）
+func (h *RegimenHandler) GetRegimen(c *gin.Context) {
+	// ユーザーIDを取得
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	regimen, err := h.medicationSvc.GetRegimen(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get regimen"})
+		return
+	}
+
+	c.JSON(http.StatusOK, regimen)
+}
+
+// RegisterRegimen はレジメンを登録するハンドラー
+func (h *RegimenHandler) RegisterRegimen(c *gin.Context) {
+	// ユーザーIDを取得
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	req, ok := h.bindRegimenRequest(c)
+	if !ok {
+		return
+	}
+
+	// 既に登録済みの場合は競合として扱う
+	if _, findErr := h.regimenRepo.GetByUserID(userID); findErr == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "regimen already exists"})
+		return
+	} else if !errors.Is(findErr, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get regimen"})
+		return
+	}
+
+	regimen := model.Regimen{UserID: userID}
+	applyRegimenRequest(&regimen, req)
+
+	if err := h.regimenRepo.Create(&regimen); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to register regimen"})
+		return
+	}
+
+	c.JSON(http.StatusOK, regimen)
+}
+
+// UpdateRegimen はレジメンを更新するハンドラー
+func (h *RegimenHandler) UpdateRegimen(c *gin.Context) {
+	// ユーザーIDを取得
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	req, ok := h.bindRegimenRequest(c)
+	if !ok {
+		return
+	}
+
+	regimen, err := h.regimenRepo.GetByUserID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "regimen not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get regimen"})
+		return
+	}
+
+	applyRegimenRequest(regimen, req)
+
+	if err := h.regimenRepo.Update(regimen); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update regimen"})
+		return
+	}
+
+	c.JSON(http.StatusOK, regimen)
+}
+
+// DeleteRegimen はレジメンを削除してデフォルト値に戻すハンドラー
+func (h *RegimenHandler) DeleteRegimen(c *gin.Context) {
+	// ユーザーIDを取得
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	if err := h.regimenRepo.DeleteByUserID(userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete regimen"})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.BaseResponse{
+		Success: true,
+		Message: "regimen deleted successfully",
+	})
+}
+
+// bindRegimenRequest はリクエストボディをバインドして検証する
+func (h *RegimenHandler) bindRegimenRequest(c *gin.Context) (*dto.RegimenRequest, bool) {
+	var req dto.RegimenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return nil, false
+	}
+
+	// 最大連続服用日数は最低連続服用日数以上である必要がある
+	if req.MaxContinuousDays > 0 && req.MaxContinuousDays < req.MinActiveDays {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "maxContinuousDays must be greater than or equal to minActiveDays"})
+		return nil, false
+	}
+
+	// 周期投与の場合は実薬日数と休薬日数が必要
+	if req.Type == model.RegimenTypeCyclic && (req.PackActiveDays == 0 || req.PackBreakDays == 0) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "packActiveDays and packBreakDays are required for cyclic regimen"})
+		return nil, false
+	}
+
+	return &req, true
+}
+
+// applyRegimenRequest はリクエストの内容をレジメンに反映する
+func applyRegimenRequest(regimen *model.Regimen, req *dto.RegimenRequest) {
+	regimen.Type = req.Type
+	if regimen.Type == "" {
+		regimen.Type = model.RegimenTypeFlexible
+	}
+
+	regimen.BleedingDaysThreshold = req.BleedingDaysThreshold
+	if regimen.BleedingDaysThreshold == 0 {
+		regimen.BleedingDaysThreshold = model.DefaultBleedingDaysThreshold
+	}
+
+	regimen.RestPeriodDays = req.RestPeriodDays
+	if regimen.RestPeriodDays == 0 {
+		regimen.RestPeriodDays = model.DefaultRestPeriodDays
+	}
+
+	regimen.MinActiveDays = req.MinActiveDays
+	regimen.MaxContinuousDays = req.MaxContinuousDays
+	regimen.PackActiveDays = req.PackActiveDays
+	regimen.PackBreakDays = req.PackBreakDays
+	regimen.PackStartDate = req.PackStartDate
+
+	regimen.MinBleedingLevel = req.MinBleedingLevel
+	if regimen.MinBleedingLevel == "" {
+		regimen.MinBleedingLevel = model.DefaultMinBleedingLevel
+	}
+
+	regimen.PillType = req.PillType
+	if regimen.PillType == "" {
+		regimen.PillType = model.DefaultPillType
+	}
+}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"net/http"
+	"okusuri-backend/internal/dto"
+	"okusuri-backend/internal/repository"
+	"okusuri-backend/pkg/helper"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type UserHandler struct {
+	userRepo *repository.UserRepository
+}
+
+func NewUserHandler(userRepo *repository.UserRepository) *UserHandler {
+	return &UserHandler{
+		userRepo: userRepo,
+	}
+}
+
+// UpdateTimezone はユーザーのタイムゾーンを更新するハンドラー
+func (h *UserHandler) UpdateTimezone(c *gin.Context) {
+	// ユーザーIDを取得
+	userID, err := helper.GetUserIDFromContext(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user ID"})
+		return
+	}
+
+	// リクエストボディを構造体にバインド
+	var req dto.UpdateTimezoneRequest
+	if bindErr := c.ShouldBindJSON(&req); bindErr != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+		return
+	}
+
+	// IANAタイムゾーン名として解釈できるか検証
+	if _, loadErr := time.LoadLocation(req.Timezone); loadErr != nil || req.Timezone == "Local" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid timezone"})
+		return
+	}
+
+	if err := h.userRepo.UpdateTimezone(userID, req.Timezone); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update timezone"})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.BaseResponse{
+		Success: true,
+		Message: "timezone updated successfully",
+	})
+}
@@ -0,0 +1,97 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"okusuri-backend/internal/repository"
+	"okusuri-backend/internal/service"
+	"time"
+)
+
+// appointmentReminderInterval は受診予定のリマインダーを確認する間隔
+const appointmentReminderInterval = 5 * time.Minute
+
+// AppointmentReminderJob はリマインダーを送る日時を過ぎた受診予定を通知するバックグラウンドジョブ
+type AppointmentReminderJob struct {
+	appointmentRepo  *repository.AppointmentRepository
+	userRepo         *repository.UserRepository
+	notificationRepo *repository.NotificationRepository
+	notificationSvc  *service.NotificationService
+}
+
+// NewAppointmentReminderJob は新しいAppointmentReminderJobを作成
+func NewAppointmentReminderJob(
+	appointmentRepo *repository.AppointmentRepository,
+	userRepo *repository.UserRepository,
+	notificationRepo *repository.NotificationRepository,
+	notificationSvc *service.NotificationService,
+) *AppointmentReminderJob {
+	return &AppointmentReminderJob{
+		appointmentRepo:  appointmentRepo,
+		userRepo:         userRepo,
+		notificationRepo: notificationRepo,
+		notificationSvc:  notificationSvc,
+	}
+}
+
+// Start はジョブを定期実行する（ctxがキャンセルされるまで）
+func (j *AppointmentReminderJob) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(appointmentReminderInterval)
+		defer ticker.Stop()
+
+		j.Run()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				j.Run()
+			}
+		}
+	}()
+}
+
+// Run はリマインダーを送る日時を過ぎた受診予定の通知を1回実行する
+func (j *AppointmentReminderJob) Run() {
+	now := j.notificationSvc.Now()
+	appointments, err := j.appointmentRepo.GetDueReminders(now)
+	if err != nil {
+		fmt.Printf("受診リマインダー: 受診予定の取得失敗: %v\n", err)
+		return
+	}
+
+	sent := 0
+	for _, appointment := range appointments {
+		// 送信前に送信済みとして記録し、複数のサーバーから重複して送らないようにする
+		claimed, err := j.appointmentRepo.ClaimReminder(appointment.ID, now)
+		if err != nil {
+			fmt.Printf("受診リマインダー: 受診予定ID: %d の記録に失敗: %v\n", appointment.ID, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		user, err := j.userRepo.FindByID(appointment.UserID)
+		if err != nil {
+			fmt.Printf("受診リマインダー: ユーザーID: %s の取得に失敗: %v\n", appointment.UserID, err)
+			continue
+		}
+
+		setting, err := j.notificationRepo.GetSettingByUserID(user.ID)
+		if err != nil || !setting.IsEnabled {
+			continue
+		}
+
+		if err := j.notificationSvc.SendAppointmentReminder(*user, *setting, appointment); err != nil {
+			fmt.Printf("受診リマインダー: 受診予定ID: %d の通知に失敗: %v\n", appointment.ID, err)
+			continue
+		}
+		sent++
+	}
+
+	if sent > 0 {
+		fmt.Printf("受診リマインダー: %d件の受診予定を通知しました\n", sent)
+	}
+}
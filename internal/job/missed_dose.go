@@ -0,0 +1,71 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"okusuri-backend/internal/repository"
+	"okusuri-backend/internal/service"
+	"time"
+)
+
+// missedDoseInterval は飲み忘れ検出を実行する間隔
+// ユーザーごとにタイムゾーンが異なるため、毎時実行して各ユーザーの日付の終わりを検出する
+const missedDoseInterval = time.Hour
+
+// MissedDoseJob は前日に服用記録がないユーザーの飲み忘れを記録するバックグラウンドジョブ
+type MissedDoseJob struct {
+	userRepo      *repository.UserRepository
+	medicationSvc *service.MedicationService
+}
+
+// NewMissedDoseJob は新しいMissedDoseJobを作成
+func NewMissedDoseJob(
+	userRepo *repository.UserRepository,
+	medicationSvc *service.MedicationService,
+) *MissedDoseJob {
+	return &MissedDoseJob{
+		userRepo:      userRepo,
+		medicationSvc: medicationSvc,
+	}
+}
+
+// Start はジョブを定期実行する（ctxがキャンセルされるまで）
+func (j *MissedDoseJob) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(missedDoseInterval)
+		defer ticker.Stop()
+
+		j.Run()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				j.Run()
+			}
+		}
+	}()
+}
+
+// Run は全ユーザーの飲み忘れ検出を1回実行する
+func (j *MissedDoseJob) Run() {
+	users, err := j.userRepo.GetAllUsers()
+	if err != nil {
+		fmt.Printf("飲み忘れ検出: ユーザー取得失敗: %v\n", err)
+		return
+	}
+
+	total := 0
+	for _, user := range users {
+		recorded, err := j.medicationSvc.RecordMissedDoses(user)
+		if err != nil {
+			fmt.Printf("飲み忘れ検出: ユーザーID: %s の処理に失敗: %v\n", user.ID, err)
+			continue
+		}
+		total += recorded
+	}
+
+	if total > 0 {
+		fmt.Printf("飲み忘れ検出: %d件の飲み忘れを記録しました\n", total)
+	}
+}
@@ -0,0 +1,72 @@
+package job
+
+import (
+	"context"
+	"fmt"
+	"okusuri-backend/internal/service"
+	"time"
+)
+
+// notificationInterval は通知時刻を確認する間隔
+const notificationInterval = time.Minute
+
+// NotificationJob はユーザーのタイムゾーンで通知時刻を迎えたユーザーに服薬リマインダーを送るバックグラウンドジョブ
+// 送信記録で通知時刻ごとに1度だけ送るため、複数のサーバーで実行しても重複しない
+type NotificationJob struct {
+	reminderSvc *service.ReminderService
+}
+
+// NewNotificationJob は新しいNotificationJobを作成
+func NewNotificationJob(reminderSvc *service.ReminderService) *NotificationJob {
+	return &NotificationJob{
+		reminderSvc: reminderSvc,
+	}
+}
+
+// Start はジョブを定期実行する（ctxがキャンセルされるまで）
+func (j *NotificationJob) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(notificationInterval)
+		defer ticker.Stop()
+
+		j.Run()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				j.Run()
+
+				// 古い送信記録は1時間ごとに削除する
+				if now.Minute() == 0 {
+					if err := j.reminderSvc.PurgeDispatches(); err != nil {
+						fmt.Printf("通知スケジューラー: 送信記録の削除に失敗: %v\n", err)
+					}
+				}
+			}
+		}
+	}()
+}
+
+// Run は通知時刻を迎えたユーザーとスヌーズした通知の送信を1回実行する
+func (j *NotificationJob) Run() {
+	sent, err := j.reminderSvc.DispatchDueReminders()
+	if err != nil {
+		fmt.Printf("通知スケジューラー: 通知の送信に失敗: %v\n", err)
+		return
+	}
+
+	if sent > 0 {
+		fmt.Printf("通知スケジューラー: %d件の通知を送信しました\n", sent)
+	}
+
+	snoozed, err := j.reminderSvc.DispatchSnoozedReminders()
+	if err != nil {
+		fmt.Printf("通知スケジューラー: スヌーズの通知の送信に失敗: %v\n", err)
+		return
+	}
+
+	if snoozed > 0 {
+		fmt.Printf("通知スケジューラー: %d件のスヌーズの通知を送信しました\n", snoozed)
+	}
+}
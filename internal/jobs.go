@@ -0,0 +1,15 @@
+package internal
+
+import (
+	"context"
+	"okusuri-backend/internal/job"
+)
+
+// StartJobs はバックグラウンドジョブを開始する（ctxがキャンセルされるまで実行）
+func StartJobs(ctx context.Context, deps *Dependencies) {
+	job.NewNotificationJob(deps.ReminderService).Start(ctx)
+	job.NewMissedDoseJob(deps.UserRepo, deps.MedicationService).Start(ctx)
+	job.NewAppointmentReminderJob(
+		deps.AppointmentRepo, deps.UserRepo, deps.NotificationRepo, deps.NotificationService,
+	).Start(ctx)
+}
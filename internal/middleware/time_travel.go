@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"net/http"
+	"okusuri-backend/internal/model"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TimeTravelHeader は指定した日時時点のレスポンスを確認するためのヘッダー（RFC3339形式）
+const TimeTravelHeader = "X-Debug-Now"
+
+// TimeTravel は管理者が開発・テスト環境で現在日時を差し替えられるようにするミドルウェア
+// Authミドルウェアの後に設定すること
+func TimeTravel() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value := c.GetHeader(TimeTravelHeader)
+		if value == "" {
+			c.Next()
+			return
+		}
+
+		// APP_ENVが明示的に開発・テスト環境の場合以外はヘッダーを無視する
+		if !timeTravelEnabled() {
+			c.Next()
+			return
+		}
+
+		if !isAdmin(c) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "time travel is only allowed for admin users"})
+			c.Abort()
+			return
+		}
+
+		now, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + TimeTravelHeader + " header"})
+			c.Abort()
+			return
+		}
+
+		// 差し替えた日時をコンテキストに保存
+		c.Set("now", now)
+
+		c.Next()
+	}
+}
+
+// timeTravelEnabled は現在日時の差し替えを許可する環境（developmentまたはtest）かどうかを判定する
+func timeTravelEnabled() bool {
+	switch os.Getenv("APP_ENV") {
+	case "development", "test":
+		return true
+	default:
+		return false
+	}
+}
+
+// isAdmin はログインユーザーが管理者（ADMIN_EMAILSに含まれるメールアドレス）かどうかを判定する
+func isAdmin(c *gin.Context) bool {
+	userInterface, exists := c.Get("user")
+	if !exists {
+		return false
+	}
+	user, ok := userInterface.(*model.User)
+	if !ok {
+		return false
+	}
+
+	for _, email := range strings.Split(os.Getenv("ADMIN_EMAILS"), ",") {
+		if email = strings.TrimSpace(email); email != "" && strings.EqualFold(email, user.Email) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"okusuri-backend/internal/model"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// timeTravelRequest は管理者としてX-Debug-Nowヘッダー付きのリクエストを送り、ステータスコードと日時が差し替えられたかどうかを返す
+func timeTravelRequest(t *testing.T) (int, bool) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	t.Setenv("ADMIN_EMAILS", "admin@example.com")
+
+	overridden := false
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user", &model.User{Email: "admin@example.com"})
+		c.Next()
+	})
+	router.Use(TimeTravel())
+	router.GET("/", func(c *gin.Context) {
+		_, overridden = c.Get("now")
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(TimeTravelHeader, "2025-06-01T09:00:00+09:00")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w.Code, overridden
+}
+
+// 現在日時の差し替えのテスト
+func TestTimeTravel(t *testing.T) {
+	t.Run("APP_ENVが未設定の場合はヘッダーを無視する", func(t *testing.T) {
+		// t.Setenvで終了時に元の値に戻してから未設定にする
+		t.Setenv("APP_ENV", "")
+		os.Unsetenv("APP_ENV")
+
+		code, overridden := timeTravelRequest(t)
+		assert.Equal(t, http.StatusOK, code)
+		assert.False(t, overridden)
+	})
+
+	t.Run("本番環境ではヘッダーを無視する", func(t *testing.T) {
+		t.Setenv("APP_ENV", "production")
+
+		code, overridden := timeTravelRequest(t)
+		assert.Equal(t, http.StatusOK, code)
+		assert.False(t, overridden)
+	})
+
+	t.Run("開発環境では管理者が日時を差し替えられる", func(t *testing.T) {
+		t.Setenv("APP_ENV", "development")
+
+		code, overridden := timeTravelRequest(t)
+		assert.Equal(t, http.StatusOK, code)
+		assert.True(t, overridden)
+	})
+}
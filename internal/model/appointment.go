@@ -0,0 +1,30 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 受診予定のリマインダーを送るタイミングのデフォルト値（前日）
+const DefaultAppointmentRemindBeforeMinutes = 24 * 60
+
+// 受診予定の構造体
+type Appointment struct {
+	ID                  uint           `json:"id" gorm:"primarykey"`
+	CreatedAt           time.Time      `json:"createdAt"`
+	UpdatedAt           time.Time      `json:"updatedAt"`
+	DeletedAt           gorm.DeletedAt `json:"deletedAt,omitempty" gorm:"index"`
+	UserID              string         `json:"userId" gorm:"not null;index"`
+	ScheduledAt         time.Time      `json:"scheduledAt" gorm:"not null;index"`                // 受診日時
+	Clinic              string         `json:"clinic" gorm:"not null"`                           // 医療機関
+	Purpose             string         `json:"purpose"`                                          // 受診の目的（例: 血圧測定、血液検査）
+	Note                string         `json:"note,omitempty" gorm:"type:text"`                  // メモ
+	RemindBeforeMinutes int            `json:"remindBeforeMinutes" gorm:"not null;default:1440"` // 受診日時の何分前にリマインダーを送るか（0は送らない）
+	ReminderSentAt      *time.Time     `json:"reminderSentAt,omitempty"`                         // リマインダーを送信した日時
+}
+
+// ReminderDueAt はリマインダーを送る日時を返す
+func (a Appointment) ReminderDueAt() time.Time {
+	return a.ScheduledAt.Add(-time.Duration(a.RemindBeforeMinutes) * time.Minute)
+}
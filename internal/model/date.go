@@ -0,0 +1,116 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// dateLayout は暦日の表記（YYYY-MM-DD）
+const dateLayout = "2006-01-02"
+
+// Date は時刻を持たない暦日（シート開始日や処方箋の有効期限など）
+// データベースにはdate型、JSONではYYYY-MM-DDとして保存し、タイムゾーンによって日付がずれないようにする
+type Date time.Time
+
+// NewDate は年月日から暦日を作成する
+func NewDate(year int, month time.Month, day int) Date {
+	return Date(time.Date(year, month, day, 0, 0, 0, 0, time.UTC))
+}
+
+// DateOf は日時の日付部分を暦日とする（ユーザーのタイムゾーンに変換済みの日時を渡すこと）
+func DateOf(t time.Time) Date {
+	return NewDate(t.Year(), t.Month(), t.Day())
+}
+
+// ParseDate はYYYY-MM-DDの文字列を暦日として解釈する
+func ParseDate(value string) (Date, error) {
+	t, err := time.Parse(dateLayout, value)
+	if err != nil {
+		return Date{}, err
+	}
+	return Date(t), nil
+}
+
+// In は暦日の指定したタイムゾーンでの0時を返す
+func (d Date) In(loc *time.Location) time.Time {
+	t := time.Time(d)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
+
+// Before は暦日が指定した暦日より前かどうかを判定する
+func (d Date) Before(other Date) bool {
+	return time.Time(d).Before(time.Time(other))
+}
+
+// After は暦日が指定した暦日より後かどうかを判定する
+func (d Date) After(other Date) bool {
+	return time.Time(d).After(time.Time(other))
+}
+
+// IsZero は暦日が未設定かどうかを判定する
+func (d Date) IsZero() bool {
+	return time.Time(d).IsZero()
+}
+
+// String は暦日をYYYY-MM-DDで返す
+func (d Date) String() string {
+	return time.Time(d).Format(dateLayout)
+}
+
+// MarshalJSON は暦日をYYYY-MM-DDの文字列として出力する
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON はYYYY-MM-DDの文字列を暦日として読み込む
+func (d *Date) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	parsed, err := ParseDate(value)
+	if err != nil {
+		return fmt.Errorf("invalid date: %s", value)
+	}
+	*d = parsed
+	return nil
+}
+
+// Value はデータベースに保存する値を返す
+func (d Date) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+// Scan はデータベースの値を読み込む（date型はUTCの0時として読み込まれる）
+func (d *Date) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case time.Time:
+		*d = DateOf(v.UTC())
+		return nil
+	case []byte:
+		return d.scanString(string(v))
+	case string:
+		return d.scanString(v)
+	default:
+		return fmt.Errorf("unsupported type for Date: %T", value)
+	}
+}
+
+func (d *Date) scanString(value string) error {
+	if len(value) > len(dateLayout) {
+		value = value[:len(dateLayout)]
+	}
+	parsed, err := ParseDate(value)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// GormDataType はデータベースの列の型を返す
+func (Date) GormDataType() string {
+	return "date"
+}
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 暦日の読み書きのテスト
+func TestDate(t *testing.T) {
+	t.Run("JSONではYYYY-MM-DDとして読み書きする", func(t *testing.T) {
+		data, err := json.Marshal(NewDate(2025, 6, 1))
+		require.NoError(t, err)
+		assert.Equal(t, `"2025-06-01"`, string(data))
+
+		var date Date
+		require.NoError(t, json.Unmarshal([]byte(`"2025-06-01"`), &date))
+		assert.Equal(t, NewDate(2025, 6, 1), date)
+
+		assert.Error(t, json.Unmarshal([]byte(`"2025-06-01T00:00:00+09:00"`), &date))
+	})
+
+	t.Run("データベースの値はタイムゾーンに関わらず同じ暦日として読み込む", func(t *testing.T) {
+		var date Date
+		require.NoError(t, date.Scan(time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)))
+		assert.Equal(t, NewDate(2025, 6, 1), date)
+
+		require.NoError(t, date.Scan("2025-06-02"))
+		assert.Equal(t, NewDate(2025, 6, 2), date)
+
+		value, err := date.Value()
+		require.NoError(t, err)
+		assert.Equal(t, "2025-06-02", value)
+	})
+
+	t.Run("指定したタイムゾーンの0時に変換する", func(t *testing.T) {
+		tokyo := time.FixedZone("JST", 9*60*60)
+		assert.Equal(t, time.Date(2025, 6, 1, 0, 0, 0, 0, tokyo), NewDate(2025, 6, 1).In(tokyo))
+	})
+}
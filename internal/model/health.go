@@ -0,0 +1,25 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 体調の記録（体重・血圧・気分・副作用）の構造体
+type HealthEntry struct {
+	ID           uint           `json:"id" gorm:"primarykey"`
+	CreatedAt    time.Time      `json:"createdAt"`
+	UpdatedAt    time.Time      `json:"updatedAt"`
+	DeletedAt    gorm.DeletedAt `json:"deletedAt,omitempty" gorm:"index"`
+	UserID       string         `json:"userId" gorm:"not null;index"`
+	MedicationID *uint          `json:"medicationId,omitempty" gorm:"index"` // 関連する薬（任意）
+	Medication   *Medication    `json:"-" gorm:"constraint:OnDelete:SET NULL"`
+	RecordedAt   time.Time      `json:"recordedAt" gorm:"not null;index"`                    // 測定・記録した日時
+	WeightKg     *float64       `json:"weightKg,omitempty"`                                  // 体重（kg）
+	SystolicBP   *int           `json:"systolicBp,omitempty"`                                // 収縮期血圧（mmHg）
+	DiastolicBP  *int           `json:"diastolicBp,omitempty"`                               // 拡張期血圧（mmHg）
+	MoodScore    *int           `json:"moodScore,omitempty"`                                 // 気分（1〜5、5が最も良い）
+	SideEffects  StringList     `json:"sideEffects" gorm:"type:jsonb;not null;default:'[]'"` // 副作用（例: 頭痛、吐き気）
+	Note         string         `json:"note,omitempty" gorm:"type:text"`                     // メモ
+}
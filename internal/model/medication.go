@@ -1,13 +1,167 @@
 package model
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 薬の服用スケジュール
+const (
+	MedicationScheduleRegimen = "regimen" // ユーザーのレジメンに従う（休薬期間あり）
+	MedicationScheduleDaily   = "daily"   // 毎日服用する（休薬期間なし）
+)
+
+// 補充を促す残り錠数のデフォルト値（1週間分）
+const DefaultRefillThresholdPills = 7
+
+// 予定時刻に対する服用タイミングの分類
+const (
+	IntakeStatusOnTime = "on_time" // 予定時刻の許容範囲内に服用
+	IntakeStatusLate   = "late"    // 許容範囲を過ぎて服用
+	IntakeStatusMissed = "missed"  // 飲み忘れ扱いとなる時間を過ぎて服用
+)
+
+// 出血の程度（軽い順）
+const (
+	BleedingLevelNone     = "none"     // 出血なし
+	BleedingLevelSpotting = "spotting" // 少量の不正出血（おりものに混じる程度）
+	BleedingLevelLight    = "light"    // 少量の出血
+	BleedingLevelMedium   = "medium"   // 中程度の出血
+	BleedingLevelHeavy    = "heavy"    // 多量の出血
+)
+
+// 記録できる症状
+const (
+	SymptomHeadache         = "headache"          // 頭痛
+	SymptomNausea           = "nausea"            // 吐き気
+	SymptomMood             = "mood"              // 気分の変化
+	SymptomCramps           = "cramps"            // 腹痛・生理痛
+	SymptomBreastTenderness = "breast_tenderness" // 乳房の張り
+	SymptomFatigue          = "fatigue"           // だるさ
+	SymptomBloating         = "bloating"          // むくみ・お腹の張り
+)
+
+// bleedingLevelRanks は出血の程度の順序
+var bleedingLevelRanks = map[string]int{
+	BleedingLevelNone:     0,
+	BleedingLevelSpotting: 1,
+	BleedingLevelLight:    2,
+	BleedingLevelMedium:   3,
+	BleedingLevelHeavy:    4,
+}
+
+// BleedingLevelRank は出血の程度の順序を返す（不明な値は出血なしとして扱う）
+func BleedingLevelRank(level string) int {
+	return bleedingLevelRanks[level]
+}
+
+// ユーザーが服用している薬の構造体
+type Medication struct {
+	ID        uint           `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time      `json:"createdAt"`
+	UpdatedAt time.Time      `json:"updatedAt"`
+	DeletedAt gorm.DeletedAt `json:"deletedAt,omitempty" gorm:"index"`
+	UserID    string         `json:"userId" gorm:"not null;index"`
+	Name      string         `json:"name" gorm:"not null"`
+	Dosage    string         `json:"dosage"`                                   // 用量（例: 1錠, 100mg）
+	Form      string         `json:"form"`                                     // 剤形（例: tablet, capsule）
+	Schedule  string         `json:"schedule" gorm:"not null;default:regimen"` // 服用スケジュール
+	Color     string         `json:"color"`                                    // 表示用の色
+	Icon      string         `json:"icon"`                                     // 表示用のアイコン
+	IsActive  bool           `json:"isActive" gorm:"default:true"`             // 服用中かどうか
+
+	ScheduledTime    string `json:"scheduledTime,omitempty"`                    // 服用予定時刻（HH:MM、ユーザーのタイムゾーン）
+	WindowMinutes    int    `json:"windowMinutes" gorm:"not null;default:60"`   // 予定時刻の前後何分までを時間通りとするか
+	LateLimitMinutes int    `json:"lateLimitMinutes" gorm:"not null;default:0"` // 予定時刻から何分を超えると飲み忘れ扱いか（0は判定しない）
+
+	PillsPerPack         int   `json:"pillsPerPack" gorm:"not null;default:0"`          // 1シートの錠数（0は在庫を管理しない）
+	PacksOnHand          int   `json:"packsOnHand" gorm:"not null;default:0"`           // 未開封のシート数
+	CurrentPackStartDate *Date `json:"currentPackStartDate,omitempty" gorm:"type:date"` // 服用中のシートを開始した日（暦日）
+	CurrentPackPillsLeft int   `json:"currentPackPillsLeft" gorm:"not null;default:0"`  // 服用中のシートの残り錠数
+	RefillThresholdPills int   `json:"refillThresholdPills" gorm:"not null;default:7"`  // 残り錠数がこれを下回ると補充を促す
+}
+
+// TracksInventory は在庫を管理している薬かどうかを判定する
+func (m Medication) TracksInventory() bool {
+	return m.PillsPerPack > 0
+}
+
+// RemainingPills は服用中のシートと未開封のシートを合わせた残り錠数を返す
+func (m Medication) RemainingPills() int {
+	return m.CurrentPackPillsLeft + m.PacksOnHand*m.PillsPerPack
+}
+
+// NeedsRefill は残り錠数が補充を促す錠数を下回っているかどうかを判定する
+func (m Medication) NeedsRefill() bool {
+	return m.TracksInventory() && m.RemainingPills() < m.RefillThresholdPills
+}
+
+// ConsumePill は1錠服用したとして在庫を減らす
+// 服用中のシートを使い切っている場合は未開封のシートを開封する（在庫がない場合は何もしない）
+// atはシートを開封した日とするため、ユーザーのタイムゾーンに変換して渡す
+func (m *Medication) ConsumePill(at time.Time) {
+	if !m.TracksInventory() {
+		return
+	}
+	if m.CurrentPackPillsLeft <= 0 {
+		if m.PacksOnHand <= 0 {
+			return
+		}
+		m.PacksOnHand--
+		m.CurrentPackPillsLeft = m.PillsPerPack
+		startDate := DateOf(at)
+		m.CurrentPackStartDate = &startDate
+	}
+	m.CurrentPackPillsLeft--
+}
+
+// ReturnPill は服用記録の削除により1錠を在庫に戻す
+// 服用中のシートが開封したばかり（残り錠数が1シートの錠数）の場合は、未開封のシートに戻して前のシートに戻す
+func (m *Medication) ReturnPill() {
+	if !m.TracksInventory() {
+		return
+	}
+	if m.CurrentPackPillsLeft >= m.PillsPerPack {
+		m.PacksOnHand++
+		m.CurrentPackPillsLeft = 0
+	}
+	m.CurrentPackPillsLeft++
+}
 
 // 服用履歴の構造体
 type MedicationLog struct {
-	ID          uint       `json:"id" gorm:"primarykey"`
-	CreatedAt   time.Time  `json:"createdAt"`
-	UpdatedAt   time.Time  `json:"updatedAt"`
-	DeletedAt   *time.Time `json:"deletedAt,omitempty" gorm:"index"`
-	UserID      string     `json:"userId" gorm:"not null;index:idx_user_id"` // uniqueIndexからindexに変更
-	HasBleeding bool       `json:"hasBleeding" gorm:"default:false"`
+	ID            uint           `json:"id" gorm:"primarykey"`
+	CreatedAt     time.Time      `json:"createdAt"`
+	UpdatedAt     time.Time      `json:"updatedAt"`
+	DeletedAt     gorm.DeletedAt `json:"deletedAt,omitempty" gorm:"index"`         // 論理削除（削除済みのログは取得・計算の対象外）
+	UserID        string         `json:"userId" gorm:"not null;index:idx_user_id"` // uniqueIndexからindexに変更
+	MedicationID  *uint          `json:"medicationId,omitempty" gorm:"index"`      // 服用した薬（未指定の場合は従来の単一の薬）
+	Medication    *Medication    `json:"-" gorm:"constraint:OnDelete:SET NULL"`
+	HasBleeding   bool           `json:"hasBleeding" gorm:"default:false"`                 // 出血の有無（BleedingLevelと連動、旧クライアント向け）
+	BleedingLevel string         `json:"bleedingLevel" gorm:"not null;default:none"`       // 出血の程度
+	PainScore     *int           `json:"painScore,omitempty"`                              // 痛みの強さ（0〜10、未記録の場合は省略）
+	Symptoms      StringList     `json:"symptoms" gorm:"type:jsonb;not null;default:'[]'"` // 症状の一覧
+	Note          string         `json:"note,omitempty" gorm:"type:text"`                  // 自由記述のメモ（例: 食後に服用、1時間後に嘔吐）
+	Tags          StringList     `json:"tags" gorm:"type:jsonb;not null;default:'[]'"`     // 検索用のタグ
+	IntakeStatus  string         `json:"intakeStatus,omitempty"`                           // 予定時刻に対する服用タイミング（予定時刻がない場合は空）
+	ScheduledAt   *time.Time     `json:"scheduledAt,omitempty"`                            // 判定に用いた予定時刻
+	DelayMinutes  int            `json:"delayMinutes"`                                     // 予定時刻からの遅れ（分、早い場合は負の値）
+	IsMissed      bool           `json:"isMissed" gorm:"default:false"`                    // 服用記録がなかった日の自動記録かどうか
+}
+
+// SetBleedingLevel は出血の程度を設定し、出血の有無を連動させる
+func (l *MedicationLog) SetBleedingLevel(level string) {
+	l.BleedingLevel = level
+	l.HasBleeding = BleedingLevelRank(level) > 0
+}
+
+// SetHasBleeding は出血の有無のみが指定された場合（旧クライアント）に出血の程度を設定する
+// 出血ありの場合は程度が不明なため少量の出血として扱う
+func (l *MedicationLog) SetHasBleeding(hasBleeding bool) {
+	if hasBleeding {
+		l.SetBleedingLevel(BleedingLevelLight)
+		return
+	}
+	l.SetBleedingLevel(BleedingLevelNone)
 }
@@ -0,0 +1,62 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 服用による在庫の減少のテスト
+func TestMedication_ConsumePill(t *testing.T) {
+	takenAt := time.Date(2025, 6, 1, 21, 0, 0, 0, time.UTC)
+
+	t.Run("服用中のシートを使い切ると未開封のシートを開封する", func(t *testing.T) {
+		medication := Medication{PillsPerPack: 28, PacksOnHand: 1, CurrentPackPillsLeft: 1}
+
+		medication.ConsumePill(takenAt)
+		assert.Equal(t, 0, medication.CurrentPackPillsLeft)
+		assert.Equal(t, 1, medication.PacksOnHand)
+
+		medication.ConsumePill(takenAt)
+		assert.Equal(t, 27, medication.CurrentPackPillsLeft)
+		assert.Equal(t, 0, medication.PacksOnHand)
+		assert.Equal(t, NewDate(2025, 6, 1), *medication.CurrentPackStartDate)
+		assert.Equal(t, 27, medication.RemainingPills())
+	})
+
+	t.Run("在庫がない場合は減らない", func(t *testing.T) {
+		medication := Medication{PillsPerPack: 28}
+		medication.ConsumePill(takenAt)
+		assert.Equal(t, 0, medication.RemainingPills())
+	})
+}
+
+// 服用記録の削除による在庫の返却のテスト
+func TestMedication_ReturnPill(t *testing.T) {
+	takenAt := time.Date(2025, 6, 1, 21, 0, 0, 0, time.UTC)
+
+	t.Run("服用中のシートに1錠戻る", func(t *testing.T) {
+		medication := Medication{PillsPerPack: 28, PacksOnHand: 1, CurrentPackPillsLeft: 10}
+		medication.ReturnPill()
+		assert.Equal(t, 11, medication.CurrentPackPillsLeft)
+		assert.Equal(t, 1, medication.PacksOnHand)
+	})
+
+	t.Run("服用と返却で在庫が元に戻る", func(t *testing.T) {
+		medication := Medication{PillsPerPack: 28, PacksOnHand: 1, CurrentPackPillsLeft: 1}
+
+		medication.ConsumePill(takenAt)
+		medication.ConsumePill(takenAt)
+		medication.ReturnPill()
+		medication.ReturnPill()
+		assert.Equal(t, 29, medication.RemainingPills())
+		assert.Equal(t, 1, medication.PacksOnHand)
+	})
+
+	t.Run("在庫を管理していない薬は変わらない", func(t *testing.T) {
+		medication := Medication{}
+		medication.ReturnPill()
+		assert.Equal(t, 0, medication.CurrentPackPillsLeft)
+	})
+}
@@ -6,6 +6,31 @@ import (
 	"gorm.io/gorm"
 )
 
+// 通知時刻のデフォルト値（服用予定時刻が登録されていない場合、ユーザーのタイムゾーン）
+const DefaultReminderTime = "21:00"
+
+// 服用記録がない場合の再通知のデフォルト値
+const (
+	DefaultFollowUpIntervalMinutes = 30 // 再通知の間隔（分）
+	DefaultFollowUpMaxCount        = 2  // 再通知の最大回数
+)
+
+// 通知する曜日
+const (
+	WeekdaySunday    = "sun"
+	WeekdayMonday    = "mon"
+	WeekdayTuesday   = "tue"
+	WeekdayWednesday = "wed"
+	WeekdayThursday  = "thu"
+	WeekdayFriday    = "fri"
+	WeekdaySaturday  = "sat"
+)
+
+// weekdayNames はtime.Weekdayに対応する曜日の値
+var weekdayNames = [...]string{
+	WeekdaySunday, WeekdayMonday, WeekdayTuesday, WeekdayWednesday, WeekdayThursday, WeekdayFriday, WeekdaySaturday,
+}
+
 // ユーザーの通知設定を管理する構造体
 type NotificationSetting struct {
 	ID           uint           `json:"id" gorm:"primarykey"`
@@ -16,4 +41,45 @@ type NotificationSetting struct {
 	Platform     string         `json:"platform" gorm:"not null;index:idx_user_platform,unique:true,part:2"`
 	IsEnabled    bool           `json:"isEnabled" gorm:"default:true"`
 	Subscription string         `json:"subscription" gorm:"type:text"` // Web Push用のサブスクリプション
+
+	ReminderTimes    StringList `json:"reminderTimes" gorm:"type:jsonb;not null;default:'[]'"`    // 通知時刻（HH:MM、空の場合は服用予定時刻）
+	ReminderWeekdays StringList `json:"reminderWeekdays" gorm:"type:jsonb;not null;default:'[]'"` // 通知する曜日（空の場合は毎日）
+	Timezone         string     `json:"timezone"`                                                 // 通知時刻のタイムゾーン（空の場合はユーザーのタイムゾーン）
+
+	FollowUpIntervalMinutes int `json:"followUpIntervalMinutes" gorm:"not null;default:30"` // 服用記録がない場合に再通知する間隔（分）
+	FollowUpMaxCount        int `json:"followUpMaxCount" gorm:"not null;default:2"`         // 再通知の最大回数（0は再通知しない）
+}
+
+// Location は通知時刻のタイムゾーンを返す（未設定・不正な場合はfallback）
+func (s NotificationSetting) Location(fallback *time.Location) *time.Location {
+	if s.Timezone != "" {
+		if loc, err := time.LoadLocation(s.Timezone); err == nil {
+			return loc
+		}
+	}
+	return fallback
+}
+
+// RemindsOn は指定した曜日に通知するかどうかを判定する
+func (s NotificationSetting) RemindsOn(weekday time.Weekday) bool {
+	return len(s.ReminderWeekdays) == 0 || s.ReminderWeekdays.Contains(weekdayNames[weekday])
+}
+
+// 通知の送信記録（複数のサーバーで同じ通知を重複して送らないために用いる）
+type NotificationDispatch struct {
+	ID        uint      `json:"id" gorm:"primarykey"`
+	CreatedAt time.Time `json:"createdAt" gorm:"index"`
+	UserID    string    `json:"userId" gorm:"not null;uniqueIndex:idx_dispatch_user_kind_slot"`
+	Kind      string    `json:"kind" gorm:"not null;uniqueIndex:idx_dispatch_user_kind_slot"` // 通知の種類
+	Slot      string    `json:"slot" gorm:"not null;uniqueIndex:idx_dispatch_user_kind_slot"` // 通知時刻（ユーザーのタイムゾーンの日時）
+}
+
+// 通知のスヌーズ（指定した日時に再通知する）
+type SnoozedReminder struct {
+	ID           uint       `json:"id" gorm:"primarykey"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	UserID       string     `json:"userId" gorm:"not null;index"`
+	MedicationID *uint      `json:"medicationId,omitempty"`         // アクションで服用記録を登録する薬
+	RemindAt     time.Time  `json:"remindAt" gorm:"not null;index"` // 再通知する日時
+	SentAt       *time.Time `json:"sentAt,omitempty"`               // 再通知を送信した日時
 }
@@ -0,0 +1,24 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 処方箋の構造体
+type Prescription struct {
+	ID           uint           `json:"id" gorm:"primarykey"`
+	CreatedAt    time.Time      `json:"createdAt"`
+	UpdatedAt    time.Time      `json:"updatedAt"`
+	DeletedAt    gorm.DeletedAt `json:"deletedAt,omitempty" gorm:"index"`
+	UserID       string         `json:"userId" gorm:"not null;index"`
+	MedicationID *uint          `json:"medicationId,omitempty" gorm:"index"` // 処方された薬（任意）
+	Medication   *Medication    `json:"-" gorm:"constraint:OnDelete:SET NULL"`
+	Prescriber   string         `json:"prescriber"`                                // 処方した医師
+	Clinic       string         `json:"clinic"`                                    // 医療機関
+	IssuedOn     Date           `json:"issuedOn" gorm:"type:date;not null"`        // 発行日（暦日）
+	ExpiresOn    Date           `json:"expiresOn" gorm:"type:date;not null;index"` // 有効期限（暦日、この日まで有効）
+	RefillsLeft  int            `json:"refillsLeft" gorm:"not null;default:0"`     // 残りのリフィル（繰り返し調剤）回数
+	Note         string         `json:"note,omitempty" gorm:"type:text"`
+}
@@ -0,0 +1,73 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// レジメンの種類
+const (
+	RegimenTypeFlexible   = "flexible"   // 出血をきっかけに休薬するフレキシブル延長投与
+	RegimenTypeCyclic     = "cyclic"     // 21/7や24/4などの周期投与
+	RegimenTypeContinuous = "continuous" // 休薬なしの連続投与
+)
+
+// ピルの種類（飲み忘れ時の対処が異なる）
+const (
+	PillTypeCombined      = "combined"       // 卵胞ホルモンと黄体ホルモンの混合型（一般的な低用量ピル）
+	PillTypeProgestinOnly = "progestin_only" // 黄体ホルモンのみのミニピル（服用時間の遅れの許容範囲が短い）
+)
+
+// レジメンのデフォルト値（フレキシブル延長投与の標準的な処方）
+const (
+	DefaultBleedingDaysThreshold = 3 // 休薬を開始する連続出血日数
+	DefaultRestPeriodDays        = 4 // 休薬期間の日数
+	DefaultMinActiveDays         = 0 // 休薬が許可されるまでの最低連続服用日数（0は制限なし）
+	DefaultMaxContinuousDays     = 0 // 最大連続服用日数（0は制限なし）
+
+	DefaultMinBleedingLevel = BleedingLevelSpotting // 連続出血日数に数える最も軽い出血の程度
+	DefaultPillType         = PillTypeCombined      // ピルの種類
+)
+
+// ユーザーごとの服薬レジメン（処方ルール）を管理する構造体
+type Regimen struct {
+	ID                    uint           `json:"id" gorm:"primarykey"`
+	CreatedAt             time.Time      `json:"createdAt"`
+	UpdatedAt             time.Time      `json:"updatedAt"`
+	DeletedAt             gorm.DeletedAt `json:"deletedAt,omitempty" gorm:"index"`
+	UserID                string         `json:"userId" gorm:"not null;uniqueIndex"`
+	Type                  string         `json:"type" gorm:"not null;default:flexible"`
+	BleedingDaysThreshold int            `json:"bleedingDaysThreshold" gorm:"not null;default:3"`
+	RestPeriodDays        int            `json:"restPeriodDays" gorm:"not null;default:4"`
+	MinActiveDays         int            `json:"minActiveDays" gorm:"not null;default:0"`
+	MaxContinuousDays     int            `json:"maxContinuousDays" gorm:"not null;default:0"`
+	PackActiveDays        int            `json:"packActiveDays" gorm:"not null;default:0"`          // 周期投与の実薬日数（例: 21, 24）
+	PackBreakDays         int            `json:"packBreakDays" gorm:"not null;default:0"`           // 周期投与の休薬・偽薬日数（例: 7, 4）
+	PackStartDate         *Date          `json:"packStartDate,omitempty" gorm:"type:date"`          // 周期投与の起点となるシート開始日（暦日）
+	MinBleedingLevel      string         `json:"minBleedingLevel" gorm:"not null;default:spotting"` // 連続出血日数に数える最も軽い出血の程度
+	PillType              string         `json:"pillType" gorm:"not null;default:combined"`         // ピルの種類（飲み忘れ時の対処に使用）
+}
+
+// NewDefaultRegimen はデフォルト値のレジメンを作成する
+func NewDefaultRegimen(userID string) Regimen {
+	return Regimen{
+		UserID:                userID,
+		Type:                  RegimenTypeFlexible,
+		BleedingDaysThreshold: DefaultBleedingDaysThreshold,
+		RestPeriodDays:        DefaultRestPeriodDays,
+		MinActiveDays:         DefaultMinActiveDays,
+		MaxContinuousDays:     DefaultMaxContinuousDays,
+		MinBleedingLevel:      DefaultMinBleedingLevel,
+		PillType:              DefaultPillType,
+	}
+}
+
+// CountsAsBleeding は出血の程度が休薬の判定に用いる連続出血日数に数えられるかどうかを判定する
+func (r Regimen) CountsAsBleeding(level string) bool {
+	minLevel := r.MinBleedingLevel
+	if minLevel == "" {
+		minLevel = DefaultMinBleedingLevel
+	}
+	return BleedingLevelRank(level) > 0 && BleedingLevelRank(level) >= BleedingLevelRank(minLevel)
+}
@@ -2,6 +2,9 @@ package model
 
 import "time"
 
+// DefaultTimezone はタイムゾーン未設定のユーザーに適用するタイムゾーン
+const DefaultTimezone = "Asia/Tokyo"
+
 // User モデル
 type User struct {
 	ID            string    `json:"id" gorm:"primary_key"`
@@ -9,6 +12,7 @@ type User struct {
 	Email         string    `json:"email" gorm:"unique"`
 	EmailVerified bool      `json:"emailVerified"`
 	Image         *string   `json:"image"`
+	Timezone      string    `json:"timezone" gorm:"not null;default:Asia/Tokyo"` // IANAタイムゾーン名
 	CreatedAt     time.Time `json:"createdAt"`
 	UpdatedAt     time.Time `json:"updatedAt"`
 }
@@ -17,6 +21,20 @@ func (User) TableName() string {
 	return "user"
 }
 
+// Location はユーザーのタイムゾーンを返す（未設定・不正な場合はデフォルト）
+func (u User) Location() *time.Location {
+	if u.Timezone != "" {
+		if loc, err := time.LoadLocation(u.Timezone); err == nil {
+			return loc
+		}
+	}
+	loc, err := time.LoadLocation(DefaultTimezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
 // Session モデル
 type Session struct {
 	ID        string    `json:"id" gorm:"primary_key;column:id"`
@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"fmt"
+	"okusuri-backend/internal/model"
+	"okusuri-backend/pkg/config"
+	"time"
+)
+
+type AppointmentRepository struct{}
+
+func NewAppointmentRepository() *AppointmentRepository {
+	return &AppointmentRepository{}
+}
+
+// Create は受診予定を登録する
+func (r *AppointmentRepository) Create(appointment *model.Appointment) error {
+	// DB接続
+	db := config.DB
+
+	if err := db.Create(appointment).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetByUserID はユーザーの受診予定を日時の早い順に取得する（fromを指定した場合はそれ以降の予定のみ）
+func (r *AppointmentRepository) GetByUserID(userID string, from *time.Time) ([]model.Appointment, error) {
+	// DB接続
+	db := config.DB
+
+	query := db.Where("user_id = ?", userID)
+	if from != nil {
+		query = query.Where("scheduled_at >= ?", *from)
+	}
+
+	var appointments []model.Appointment
+	if err := query.Order("scheduled_at ASC").Find(&appointments).Error; err != nil {
+		return nil, err
+	}
+
+	return appointments, nil
+}
+
+// GetByID はIDに基づいて受診予定を取得する
+func (r *AppointmentRepository) GetByID(userID string, appointmentID uint) (*model.Appointment, error) {
+	// DB接続
+	db := config.DB
+
+	var appointment model.Appointment
+	if err := db.Where("id = ? AND user_id = ?", appointmentID, userID).First(&appointment).Error; err != nil {
+		return nil, err
+	}
+
+	return &appointment, nil
+}
+
+// Update は受診予定を更新する
+func (r *AppointmentRepository) Update(appointment *model.Appointment) error {
+	// DB接続
+	db := config.DB
+
+	return db.Save(appointment).Error
+}
+
+// Delete は指定されたIDの受診予定を削除する
+func (r *AppointmentRepository) Delete(userID string, appointmentID uint) error {
+	// DB接続
+	db := config.DB
+
+	result := db.Where("id = ? AND user_id = ?", appointmentID, userID).Delete(&model.Appointment{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	// 削除された行数が0の場合は、受診予定が見つからないエラーを返す
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("appointment not found or user not authorized")
+	}
+
+	return nil
+}
+
+// GetDueReminders はリマインダーを送る日時を過ぎた未送信の受診予定を取得する（受診日時を過ぎたものは除く）
+func (r *AppointmentRepository) GetDueReminders(now time.Time) ([]model.Appointment, error) {
+	// DB接続
+	db := config.DB
+
+	var appointments []model.Appointment
+	err := db.Where("reminder_sent_at IS NULL AND remind_before_minutes > 0 AND scheduled_at > ?", now).
+		Where("scheduled_at - remind_before_minutes * INTERVAL '1 minute' <= ?", now).
+		Order("scheduled_at ASC").
+		Find(&appointments).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return appointments, nil
+}
+
+// ClaimReminder はリマインダーを送信済みとして記録する
+// 他のサーバーが既に記録していた場合はfalseを返す（複数のサーバーで同じリマインダーを送らないため）
+func (r *AppointmentRepository) ClaimReminder(appointmentID uint, at time.Time) (bool, error) {
+	// DB接続
+	db := config.DB
+
+	result := db.Model(&model.Appointment{}).
+		Where("id = ? AND reminder_sent_at IS NULL", appointmentID).
+		Update("reminder_sent_at", at)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"fmt"
+	"okusuri-backend/internal/model"
+	"okusuri-backend/pkg/config"
+	"time"
+)
+
+type HealthRepository struct{}
+
+func NewHealthRepository() *HealthRepository {
+	return &HealthRepository{}
+}
+
+// Create は体調の記録を登録する
+func (r *HealthRepository) Create(entry *model.HealthEntry) error {
+	// DB接続
+	db := config.DB
+
+	if err := db.Create(entry).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetByUserID は指定期間の体調の記録を記録日時の新しい順に取得する（fromとtoはnilの場合は制限なし）
+func (r *HealthRepository) GetByUserID(userID string, from, to *time.Time) ([]model.HealthEntry, error) {
+	// DB接続
+	db := config.DB
+
+	query := db.Where("user_id = ?", userID)
+	if from != nil {
+		query = query.Where("recorded_at >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("recorded_at < ?", *to)
+	}
+
+	var entries []model.HealthEntry
+	if err := query.Order("recorded_at DESC").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// GetByID はIDに基づいて体調の記録を取得する
+func (r *HealthRepository) GetByID(userID string, entryID uint) (*model.HealthEntry, error) {
+	// DB接続
+	db := config.DB
+
+	var entry model.HealthEntry
+	if err := db.Where("id = ? AND user_id = ?", entryID, userID).First(&entry).Error; err != nil {
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+// Update は体調の記録を更新する
+func (r *HealthRepository) Update(entry *model.HealthEntry) error {
+	// DB接続
+	db := config.DB
+
+	if err := db.Save(entry).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Delete は体調の記録を論理削除する
+func (r *HealthRepository) Delete(userID string, entryID uint) error {
+	// DB接続
+	db := config.DB
+
+	result := db.Where("id = ? AND user_id = ?", entryID, userID).Delete(&model.HealthEntry{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	// 削除された行数が0の場合は、記録が見つからないエラーを返す
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("health entry not found or user not authorized")
+	}
+
+	return nil
+}
@@ -4,7 +4,11 @@ import (
 	"fmt"
 	"okusuri-backend/internal/model"
 	"okusuri-backend/pkg/config"
+	"strings"
 	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type MedicationRepository struct{}
@@ -26,6 +30,71 @@ func (r *MedicationRepository) RegisterLog(userID string, log model.MedicationLo
 	return nil
 }
 
+// RegisterLogWithDispatch は処理の実行記録を登録し、登録できた場合のみ服用記録を登録する
+// 複数のサーバーで同じ処理が実行されても服用記録が重複しないよう、1つのトランザクションで行う
+// 実行記録が既にある場合（他のサーバーが処理済み）はfalseを返す
+func (r *MedicationRepository) RegisterLogWithDispatch(userID string, log model.MedicationLog, kind, slot string) (bool, error) {
+	// DB接続
+	db := config.DB
+
+	claimed := false
+	err := db.Transaction(func(tx *gorm.DB) error {
+		dispatch := model.NotificationDispatch{
+			CreatedAt: log.CreatedAt,
+			UserID:    userID,
+			Kind:      kind,
+			Slot:      slot,
+		}
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&dispatch)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		claimed = true
+		return tx.Create(&log).Error
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return claimed, nil
+}
+
+// DeleteMissedLogs は指定期間の飲み忘れの自動記録を削除する（薬の指定がない場合は薬の指定がない記録が対象）
+func (r *MedicationRepository) DeleteMissedLogs(userID string, medicationID *uint, from, to time.Time) error {
+	// DB接続
+	db := config.DB
+
+	query := db.Unscoped().
+		Where("user_id = ? AND is_missed = ? AND created_at >= ? AND created_at < ?", userID, true, from, to)
+	if medicationID != nil {
+		query = query.Where("medication_id = ?", *medicationID)
+	} else {
+		query = query.Where("medication_id IS NULL")
+	}
+
+	return query.Delete(&model.MedicationLog{}).Error
+}
+
+// HasLogSince は指定日時以降に服用記録（飲み忘れの自動記録を除く）があるかどうかを判定する
+func (r *MedicationRepository) HasLogSince(userID string, since time.Time) (bool, error) {
+	// DB接続
+	db := config.DB
+
+	var count int64
+	err := db.Model(&model.MedicationLog{}).
+		Where("user_id = ? AND created_at >= ? AND is_missed = ?", userID, since, false).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
 // GetLogsByUserID はユーザーIDに基づいて服用履歴をデータベースから取得する
 func (r *MedicationRepository) GetLogsByUserID(userID string) ([]model.MedicationLog, error) {
 	// DB接続
@@ -40,6 +109,114 @@ func (r *MedicationRepository) GetLogsByUserID(userID string) ([]model.Medicatio
 	return logs, nil
 }
 
+// HistoryVersion はユーザーの服薬履歴の変更を検知するための値
+// 服薬記録の登録・更新・削除・復元、薬やレジメンの変更のいずれでも値が変わる
+type HistoryVersion struct {
+	LogCount             int64
+	LogsUpdatedAt        *time.Time
+	MedicationsUpdatedAt *time.Time
+	RegimenUpdatedAt     *time.Time
+}
+
+// GetHistoryVersion はユーザーの服薬履歴の件数と最終更新日時（削除日時を含む）を取得する
+func (r *MedicationRepository) GetHistoryVersion(userID string) (*HistoryVersion, error) {
+	// DB接続
+	db := config.DB
+
+	// 論理削除はupdated_atを更新しないため、deleted_atも最終更新日時に含める
+	var version HistoryVersion
+	err := db.Raw(`SELECT
+		(SELECT COUNT(*) FROM medication_logs WHERE user_id = @user AND deleted_at IS NULL) AS log_count,
+		(SELECT MAX(GREATEST(updated_at, deleted_at)) FROM medication_logs WHERE user_id = @user) AS logs_updated_at,
+		(SELECT MAX(GREATEST(updated_at, deleted_at)) FROM medications WHERE user_id = @user) AS medications_updated_at,
+		(SELECT MAX(GREATEST(updated_at, deleted_at)) FROM regimens WHERE user_id = @user) AS regimen_updated_at`,
+		map[string]interface{}{"user": userID}).
+		Scan(&version).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &version, nil
+}
+
+// 服薬ログ一覧の並び順
+const (
+	LogOrderAsc  = "asc"
+	LogOrderDesc = "desc"
+)
+
+// LogCursor はページングの位置を表す（直前のページの最後のログ）
+type LogCursor struct {
+	CreatedAt time.Time
+	ID        uint
+}
+
+// LogFilter は服薬ログ一覧の取得条件
+type LogFilter struct {
+	From   *time.Time // この日時以降のログ（含む）
+	To     *time.Time // この日時より前のログ（含まない）
+	Limit  int        // 取得件数の上限（0は無制限）
+	Order  string     // 作成日時の並び順（asc / desc、省略時はdesc）
+	Cursor *LogCursor // 指定された位置より後のログを取得する
+	Query  string     // メモの部分一致検索（大文字小文字を区別しない）
+	Tag    string     // 指定したタグを含むログのみ
+}
+
+// FindLogs は取得条件に基づいて服薬ログを作成日時順に取得する
+func (r *MedicationRepository) FindLogs(userID string, filter LogFilter) ([]model.MedicationLog, error) {
+	// DB接続
+	db := config.DB
+
+	query := db.Where("user_id = ?", userID)
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+	if filter.Query != "" {
+		query = query.Where("note ILIKE ?", "%"+escapeLike(filter.Query)+"%")
+	}
+	if filter.Tag != "" {
+		tags, err := model.StringList{filter.Tag}.Value()
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("tags @> ?::jsonb", tags)
+	}
+
+	// 同時刻のログがあってもページ境界がずれないよう、IDも含めて比較する
+	if filter.Order == LogOrderAsc {
+		if filter.Cursor != nil {
+			query = query.Where("(created_at > ? OR (created_at = ? AND id > ?))",
+				filter.Cursor.CreatedAt, filter.Cursor.CreatedAt, filter.Cursor.ID)
+		}
+		query = query.Order("created_at ASC").Order("id ASC")
+	} else {
+		if filter.Cursor != nil {
+			query = query.Where("(created_at < ? OR (created_at = ? AND id < ?))",
+				filter.Cursor.CreatedAt, filter.Cursor.CreatedAt, filter.Cursor.ID)
+		}
+		query = query.Order("created_at DESC").Order("id DESC")
+	}
+
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	var logs []model.MedicationLog
+	if err := query.Find(&logs).Error; err != nil {
+		return nil, err
+	}
+
+	return logs, nil
+}
+
+// escapeLike はLIKE検索のワイルドカード文字をエスケープする
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
+
 // GetLogByID はIDに基づいて単一の服薬ログを取得する
 func (r *MedicationRepository) GetLogByID(userID string, logID uint) (*model.MedicationLog, error) {
 	// DB接続
@@ -55,14 +232,22 @@ func (r *MedicationRepository) GetLogByID(userID string, logID uint) (*model.Med
 }
 
 // UpdateLog は指定されたIDの服薬ログを更新する
-func (r *MedicationRepository) UpdateLog(userID string, logID uint, hasBleeding bool) error {
+func (r *MedicationRepository) UpdateLog(userID string, logID uint, log model.MedicationLog) error {
 	// DB接続
 	db := config.DB
 
-	// ユーザーIDとログIDに基づいてログを更新
+	// ユーザーIDとログIDに基づいて出血・痛み・症状・メモ・タグを更新
 	result := db.Model(&model.MedicationLog{}).
 		Where("id = ? AND user_id = ?", logID, userID).
-		Update("has_bleeding", hasBleeding)
+		Select("has_bleeding", "bleeding_level", "pain_score", "symptoms", "note", "tags").
+		Updates(&model.MedicationLog{
+			HasBleeding:   log.HasBleeding,
+			BleedingLevel: log.BleedingLevel,
+			PainScore:     log.PainScore,
+			Symptoms:      log.Symptoms,
+			Note:          log.Note,
+			Tags:          log.Tags,
+		})
 
 	if result.Error != nil {
 		return result.Error
@@ -76,49 +261,187 @@ func (r *MedicationRepository) UpdateLog(userID string, logID uint, hasBleeding
 	return nil
 }
 
-// GetConsecutiveDays はユーザーの連続服薬日数を計算する
-func (r *MedicationRepository) GetConsecutiveDays(userID string) (int, error) {
+// DeleteLog は指定されたIDの服薬ログを論理削除する
+func (r *MedicationRepository) DeleteLog(userID string, logID uint) error {
+	// DB接続
 	db := config.DB
 
-	// ユーザーの服薬ログを日付降順で取得
-	var logs []model.MedicationLog
-	if err := db.Where("user_id = ?", userID).
-		Order("created_at DESC").
-		Find(&logs).Error; err != nil {
-		return 0, err
+	result := db.Where("id = ? AND user_id = ?", logID, userID).Delete(&model.MedicationLog{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	// 削除された行数が0の場合は、ログが見つからないエラーを返す
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("log not found or user not authorized")
+	}
+
+	return nil
+}
+
+// RestoreLog は論理削除された服薬ログを復元する
+func (r *MedicationRepository) RestoreLog(userID string, logID uint) error {
+	// DB接続
+	db := config.DB
+
+	// 削除済みのログを対象にするためUnscopedを使用
+	result := db.Unscoped().Model(&model.MedicationLog{}).
+		Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", logID, userID).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return result.Error
 	}
 
-	if len(logs) == 0 {
-		return 0, nil
+	// 復元された行数が0の場合は、削除済みのログが見つからないエラーを返す
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("deleted log not found or user not authorized")
 	}
 
-	// 連続日数をカウント
-	consecutiveDays := 1
-	today := time.Now().Truncate(24 * time.Hour)
+	return nil
+}
+
+// CreateMedication は薬を登録する
+func (r *MedicationRepository) CreateMedication(medication *model.Medication) error {
+	// DB接続
+	db := config.DB
 
-	// 最新の記録が今日かどうかチェック
-	latestLog := logs[0]
-	latestDate := latestLog.CreatedAt.Truncate(24 * time.Hour)
+	return db.Create(medication).Error
+}
+
+// GetMedicationsByUserID はユーザーが登録した薬の一覧を取得する
+func (r *MedicationRepository) GetMedicationsByUserID(userID string) ([]model.Medication, error) {
+	// DB接続
+	db := config.DB
 
-	// 最新の記録が今日でない場合は連続日数は0
-	if !latestDate.Equal(today) {
-		return 0, nil
+	var medications []model.Medication
+	if err := db.Where("user_id = ?", userID).Order("id ASC").Find(&medications).Error; err != nil {
+		return nil, err
 	}
 
-	// 前日から遡って連続日数をカウント
-	expectedDate := today.AddDate(0, 0, -1)
+	return medications, nil
+}
+
+// GetMedicationsWithDeleted は削除済みの薬を含めてユーザーが登録した薬の一覧を取得する
+// 削除済みの薬のログも残るため、ログを薬ごとに分類する場合に用いる
+func (r *MedicationRepository) GetMedicationsWithDeleted(userID string) ([]model.Medication, error) {
+	// DB接続
+	db := config.DB
+
+	var medications []model.Medication
+	if err := db.Unscoped().Where("user_id = ?", userID).Order("id ASC").Find(&medications).Error; err != nil {
+		return nil, err
+	}
+
+	return medications, nil
+}
+
+// GetMedicationByID はIDに基づいてユーザーの薬を取得する
+func (r *MedicationRepository) GetMedicationByID(userID string, medicationID uint) (*model.Medication, error) {
+	// DB接続
+	db := config.DB
+
+	var medication model.Medication
+	if err := db.Where("id = ? AND user_id = ?", medicationID, userID).First(&medication).Error; err != nil {
+		return nil, err
+	}
+
+	return &medication, nil
+}
+
+// UpdateMedication は薬の情報を更新する
+func (r *MedicationRepository) UpdateMedication(medication *model.Medication) error {
+	// DB接続
+	db := config.DB
+
+	return db.Save(medication).Error
+}
+
+// ConsumePill は薬の在庫を1錠減らす（同時に服用記録が登録されても在庫がずれないよう行をロックする）
+func (r *MedicationRepository) ConsumePill(userID string, medicationID uint, at time.Time) (*model.Medication, error) {
+	return r.updateInventory(userID, medicationID, func(medication *model.Medication) {
+		medication.ConsumePill(at)
+	})
+}
+
+// ReturnPill は服用記録の削除により薬の在庫を1錠戻す
+func (r *MedicationRepository) ReturnPill(userID string, medicationID uint) (*model.Medication, error) {
+	return r.updateInventory(userID, medicationID, func(medication *model.Medication) {
+		medication.ReturnPill()
+	})
+}
+
+// updateInventory は薬の行をロックして在庫を更新する（在庫を管理していない薬は更新しない）
+func (r *MedicationRepository) updateInventory(
+	userID string, medicationID uint, update func(medication *model.Medication),
+) (*model.Medication, error) {
+	// DB接続
+	db := config.DB
 
-	for i := 1; i < len(logs); i++ {
-		logDate := logs[i].CreatedAt.Truncate(24 * time.Hour)
+	var medication model.Medication
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND user_id = ?", medicationID, userID).
+			First(&medication).Error; err != nil {
+			return err
+		}
 
-		if logDate.Equal(expectedDate) {
-			consecutiveDays++
-			expectedDate = expectedDate.AddDate(0, 0, -1)
-		} else {
-			// 連続していない場合は終了
-			break
+		if !medication.TracksInventory() {
+			return nil
 		}
+		update(&medication)
+
+		return tx.Model(&medication).
+			Select("packs_on_hand", "current_pack_start_date", "current_pack_pills_left").
+			Updates(&medication).Error
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return consecutiveDays, nil
+	return &medication, nil
+}
+
+// AddPacks は未開封のシートを追加する（pillsPerPackが0より大きい場合は1シートの錠数も更新する）
+func (r *MedicationRepository) AddPacks(userID string, medicationID uint, packs, pillsPerPack int) error {
+	// DB接続
+	db := config.DB
+
+	updates := map[string]interface{}{
+		"packs_on_hand": gorm.Expr("packs_on_hand + ?", packs),
+	}
+	if pillsPerPack > 0 {
+		updates["pills_per_pack"] = pillsPerPack
+	}
+
+	result := db.Model(&model.Medication{}).
+		Where("id = ? AND user_id = ?", medicationID, userID).
+		Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	// 更新された行数が0の場合は、薬が見つからないエラーを返す
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("medication not found or user not authorized")
+	}
+
+	return nil
+}
+
+// DeleteMedication は指定されたIDの薬を削除する
+func (r *MedicationRepository) DeleteMedication(userID string, medicationID uint) error {
+	// DB接続
+	db := config.DB
+
+	result := db.Where("id = ? AND user_id = ?", medicationID, userID).Delete(&model.Medication{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	// 削除された行数が0の場合は、薬が見つからないエラーを返す
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("medication not found or user not authorized")
+	}
+
+	return nil
 }
@@ -3,6 +3,10 @@ package repository
 import (
 	"okusuri-backend/internal/model"
 	"okusuri-backend/pkg/config"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type NotificationRepository struct{}
@@ -25,12 +29,18 @@ func (r *NotificationRepository) GetSettingByUserID(userID string) (*model.Notif
 	return &setting, nil
 }
 
-// RegisterSetting は通知設定を登録する
-func (r *NotificationRepository) RegisterSetting(setting *model.NotificationSetting) error {
+// RegisterSetting は通知設定を登録する（同じプラットフォームの設定が既にある場合は更新する）
+// 既存の設定を更新する場合、有効・無効と購読情報以外はcolumnsに指定した列のみ更新し、それ以外は維持する
+func (r *NotificationRepository) RegisterSetting(setting *model.NotificationSetting, columns ...string) error {
 	// DB接続
 	db := config.DB
 
-	if err := db.Create(setting).Error; err != nil {
+	updates := append([]string{"updated_at", "deleted_at", "is_enabled", "subscription"}, columns...)
+	err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "platform"}},
+		DoUpdates: clause.AssignmentColumns(updates),
+	}).Create(setting).Error
+	if err != nil {
 		return err
 	}
 
@@ -49,3 +59,110 @@ func (r *NotificationRepository) GetAllSettings() ([]model.NotificationSetting,
 
 	return settings, nil
 }
+
+// ClaimDispatch は通知の送信記録を登録する
+// 同じユーザー・種類・通知時刻の記録が既にある場合（他のサーバーが送信済み）はfalseを返す
+func (r *NotificationRepository) ClaimDispatch(userID, kind, slot string, at time.Time) (bool, error) {
+	// DB接続
+	db := config.DB
+
+	dispatch := model.NotificationDispatch{
+		CreatedAt: at,
+		UserID:    userID,
+		Kind:      kind,
+		Slot:      slot,
+	}
+	result := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&dispatch)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// DeleteDispatchesBefore は指定日時より前の通知の送信記録を削除する
+func (r *NotificationRepository) DeleteDispatchesBefore(before time.Time) error {
+	// DB接続
+	db := config.DB
+
+	return db.Where("created_at < ?", before).Delete(&model.NotificationDispatch{}).Error
+}
+
+// HasDispatch は通知の送信記録があるかどうかを判定する
+func (r *NotificationRepository) HasDispatch(userID, kind, slot string) (bool, error) {
+	// DB接続
+	db := config.DB
+
+	var count int64
+	err := db.Model(&model.NotificationDispatch{}).
+		Where("user_id = ? AND kind = ? AND slot = ?", userID, kind, slot).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// CreateSnoozeWithDispatch は通知の送信記録を登録し、登録できた場合のみスヌーズを登録する
+// 送信記録とスヌーズは1つのトランザクションで登録し、送信記録が既にある場合はfalseを返す
+func (r *NotificationRepository) CreateSnoozeWithDispatch(
+	snooze *model.SnoozedReminder, kind, slot string, at time.Time,
+) (bool, error) {
+	// DB接続
+	db := config.DB
+
+	claimed := false
+	err := db.Transaction(func(tx *gorm.DB) error {
+		dispatch := model.NotificationDispatch{
+			CreatedAt: at,
+			UserID:    snooze.UserID,
+			Kind:      kind,
+			Slot:      slot,
+		}
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&dispatch)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		claimed = true
+		return tx.Create(snooze).Error
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return claimed, nil
+}
+
+// GetDueSnoozes は再通知する日時を過ぎた未送信のスヌーズを取得する
+func (r *NotificationRepository) GetDueSnoozes(now time.Time) ([]model.SnoozedReminder, error) {
+	// DB接続
+	db := config.DB
+
+	var snoozes []model.SnoozedReminder
+	if err := db.Where("sent_at IS NULL AND remind_at <= ?", now).Order("remind_at ASC").Find(&snoozes).Error; err != nil {
+		return nil, err
+	}
+
+	return snoozes, nil
+}
+
+// ClaimSnooze はスヌーズを送信済みとして記録する
+// 他のサーバーが既に記録していた場合はfalseを返す（複数のサーバーで同じ再通知を送らないため）
+func (r *NotificationRepository) ClaimSnooze(snoozeID uint, at time.Time) (bool, error) {
+	// DB接続
+	db := config.DB
+
+	result := db.Model(&model.SnoozedReminder{}).
+		Where("id = ? AND sent_at IS NULL", snoozeID).
+		Update("sent_at", at)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
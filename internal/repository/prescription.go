@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"fmt"
+	"okusuri-backend/internal/model"
+	"okusuri-backend/pkg/config"
+)
+
+type PrescriptionRepository struct{}
+
+func NewPrescriptionRepository() *PrescriptionRepository {
+	return &PrescriptionRepository{}
+}
+
+// Create は処方箋を登録する
+func (r *PrescriptionRepository) Create(prescription *model.Prescription) error {
+	// DB接続
+	db := config.DB
+
+	if err := db.Create(prescription).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetByUserID はユーザーの処方箋を発行日の新しい順に取得する
+func (r *PrescriptionRepository) GetByUserID(userID string) ([]model.Prescription, error) {
+	// DB接続
+	db := config.DB
+
+	var prescriptions []model.Prescription
+	if err := db.Where("user_id = ?", userID).Order("issued_on DESC").Find(&prescriptions).Error; err != nil {
+		return nil, err
+	}
+
+	return prescriptions, nil
+}
+
+// GetByID はIDに基づいて処方箋を取得する
+func (r *PrescriptionRepository) GetByID(userID string, prescriptionID uint) (*model.Prescription, error) {
+	// DB接続
+	db := config.DB
+
+	var prescription model.Prescription
+	if err := db.Where("id = ? AND user_id = ?", prescriptionID, userID).First(&prescription).Error; err != nil {
+		return nil, err
+	}
+
+	return &prescription, nil
+}
+
+// Update は処方箋を更新する
+func (r *PrescriptionRepository) Update(prescription *model.Prescription) error {
+	// DB接続
+	db := config.DB
+
+	return db.Save(prescription).Error
+}
+
+// Delete は指定されたIDの処方箋を削除する
+func (r *PrescriptionRepository) Delete(userID string, prescriptionID uint) error {
+	// DB接続
+	db := config.DB
+
+	result := db.Where("id = ? AND user_id = ?", prescriptionID, userID).Delete(&model.Prescription{})
+	if result.Error != nil {
+		return result.Error
+	}
+
+	// 削除された行数が0の場合は、処方箋が見つからないエラーを返す
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("prescription not found or user not authorized")
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"okusuri-backend/internal/model"
+	"okusuri-backend/pkg/config"
+)
+
+type RegimenRepository struct{}
+
+func NewRegimenRepository() *RegimenRepository {
+	return &RegimenRepository{}
+}
+
+// GetByUserID はユーザーIDに基づいてレジメンを取得する
+func (r *RegimenRepository) GetByUserID(userID string) (*model.Regimen, error) {
+	// DB接続
+	db := config.DB
+
+	var regimen model.Regimen
+	if err := db.Where("user_id = ?", userID).First(&regimen).Error; err != nil {
+		return nil, err
+	}
+
+	return &regimen, nil
+}
+
+// Create はレジメンを登録する
+func (r *RegimenRepository) Create(regimen *model.Regimen) error {
+	// DB接続
+	db := config.DB
+
+	if err := db.Create(regimen).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Update はレジメンを更新する
+func (r *RegimenRepository) Update(regimen *model.Regimen) error {
+	// DB接続
+	db := config.DB
+
+	if err := db.Save(regimen).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteByUserID はユーザーのレジメンを削除する
+func (r *RegimenRepository) DeleteByUserID(userID string) error {
+	// DB接続
+	db := config.DB
+
+	// 論理削除ではなく物理削除し、再登録時のユニーク制約違反を防ぐ
+	return db.Unscoped().Where("user_id = ?", userID).Delete(&model.Regimen{}).Error
+}
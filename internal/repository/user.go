@@ -80,6 +80,11 @@ func (r *UserRepository) Update(user *model.User) error {
 	return r.db.Save(user).Error
 }
 
+// UpdateTimezone はユーザーのタイムゾーンを更新
+func (r *UserRepository) UpdateTimezone(id string, timezone string) error {
+	return r.db.Model(&model.User{}).Where("id = ?", id).Update("timezone", timezone).Error
+}
+
 // Delete はユーザーを削除
 func (r *UserRepository) Delete(id string) error {
 	return r.db.Delete(&model.User{}, "id = ?", id).Error
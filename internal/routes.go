@@ -3,34 +3,33 @@ package internal
 import (
 	"okusuri-backend/internal/handler"
 	"okusuri-backend/internal/middleware"
-	"okusuri-backend/internal/repository"
-	"okusuri-backend/internal/service"
 
 	"github.com/gin-gonic/gin"
 )
 
-func SetupRoutes() *gin.Engine {
-	// リポジトリの初期化
-	userRepo := repository.NewUserRepository()
-	sessionRepo := repository.NewSessionRepository(userRepo.GetDB())
-	accountRepo := repository.NewAccountRepository(userRepo.GetDB())
-	medicationRepo := repository.NewMedicationRepository()
-	notificationRepo := repository.NewNotificationRepository()
-
-	// サービスの初期化
-	notificationService := service.NewNotificationService()
-	medicationService := service.NewMedicationService(medicationRepo)
+func SetupRoutes(deps *Dependencies) *gin.Engine {
+	userRepo := deps.UserRepo
+	sessionRepo := deps.SessionRepo
+	accountRepo := deps.AccountRepo
+	medicationRepo := deps.MedicationRepo
+	notificationRepo := deps.NotificationRepo
+	regimenRepo := deps.RegimenRepo
+	reminderService := deps.ReminderService
+	medicationService := deps.MedicationService
+	healthRepo := deps.HealthRepo
+	healthService := deps.HealthService
+	prescriptionRepo := deps.PrescriptionRepo
+	appointmentRepo := deps.AppointmentRepo
 
 	// ハンドラーの初期化
 	authHandler := handler.NewAuthHandler(userRepo, sessionRepo, accountRepo)
-	medicationHandler := handler.NewMedicationHandler(medicationRepo)
-	notificationHandler := handler.NewNotificationHandler(
-		notificationRepo,
-		userRepo,
-		notificationService,
-		medicationRepo,
-		medicationService,
-	)
+	medicationHandler := handler.NewMedicationHandler(medicationRepo, medicationService, healthService)
+	healthHandler := handler.NewHealthHandler(healthRepo, medicationRepo, healthService)
+	prescriptionHandler := handler.NewPrescriptionHandler(prescriptionRepo, medicationRepo)
+	appointmentHandler := handler.NewAppointmentHandler(appointmentRepo)
+	regimenHandler := handler.NewRegimenHandler(regimenRepo, medicationService)
+	userHandler := handler.NewUserHandler(userRepo)
+	notificationHandler := handler.NewNotificationHandler(notificationRepo, reminderService)
 
 	// Ginのルーターを作成
 	router := gin.Default()
@@ -56,10 +55,14 @@ func SetupRoutes() *gin.Engine {
 			auth.POST("/signout", authHandler.SignOut)
 		}
 
-		api.POST(("/notification"), notificationHandler.SendNotification)
+		api.POST("/notification/action", notificationHandler.PerformAction)
 
 		// 新しいエンドポイントを追加
-		api.GET("/medication-status", middleware.Auth(userRepo), medicationHandler.GetMedicationStatus)
+		api.GET("/medication-status", middleware.Auth(userRepo), middleware.TimeTravel(), medicationHandler.GetMedicationStatus)
+		api.GET("/medication-status/guidance", middleware.Auth(userRepo), medicationHandler.GetGuidance)
+		api.GET("/medication-calendar", middleware.Auth(userRepo), medicationHandler.GetMedicationCalendar)
+		api.GET("/cycles", middleware.Auth(userRepo), medicationHandler.GetCycleHistory)
+		api.GET("/medication-stats", middleware.Auth(userRepo), medicationHandler.GetMedicationStats)
 
 		notificationSetting := api.Group("/notification/setting")
 		notificationSetting.Use(middleware.Auth(userRepo))
@@ -75,6 +78,65 @@ func SetupRoutes() *gin.Engine {
 			medicationLog.GET("", medicationHandler.GetLogs)
 			medicationLog.GET("/:id", medicationHandler.GetLogByID)
 			medicationLog.PATCH("/:id", medicationHandler.UpdateLog)
+			medicationLog.DELETE("/:id", medicationHandler.DeleteLog)
+			medicationLog.POST("/:id/restore", medicationHandler.RestoreLog)
+		}
+
+		user := api.Group("/user")
+		user.Use(middleware.Auth(userRepo))
+		{
+			user.PUT("/timezone", userHandler.UpdateTimezone)
+		}
+
+		medications := api.Group("/medications")
+		medications.Use(middleware.Auth(userRepo))
+		{
+			medications.GET("", medicationHandler.GetMedications)
+			medications.POST("", medicationHandler.RegisterMedication)
+			medications.GET("/:id", medicationHandler.GetMedicationByID)
+			medications.PUT("/:id", medicationHandler.UpdateMedication)
+			medications.DELETE("/:id", medicationHandler.DeleteMedication)
+			medications.POST("/:id/packs", medicationHandler.PurchasePacks)
+		}
+
+		healthEntries := api.Group("/health-entries")
+		healthEntries.Use(middleware.Auth(userRepo))
+		{
+			healthEntries.GET("", healthHandler.GetEntries)
+			healthEntries.POST("", healthHandler.RegisterEntry)
+			healthEntries.GET("/trends", healthHandler.GetTrends)
+			healthEntries.GET("/:id", healthHandler.GetEntryByID)
+			healthEntries.PUT("/:id", healthHandler.UpdateEntry)
+			healthEntries.DELETE("/:id", healthHandler.DeleteEntry)
+		}
+
+		prescriptions := api.Group("/prescriptions")
+		prescriptions.Use(middleware.Auth(userRepo))
+		{
+			prescriptions.GET("", prescriptionHandler.GetPrescriptions)
+			prescriptions.POST("", prescriptionHandler.RegisterPrescription)
+			prescriptions.GET("/:id", prescriptionHandler.GetPrescriptionByID)
+			prescriptions.PUT("/:id", prescriptionHandler.UpdatePrescription)
+			prescriptions.DELETE("/:id", prescriptionHandler.DeletePrescription)
+		}
+
+		appointments := api.Group("/appointments")
+		appointments.Use(middleware.Auth(userRepo))
+		{
+			appointments.GET("", appointmentHandler.GetAppointments)
+			appointments.POST("", appointmentHandler.RegisterAppointment)
+			appointments.GET("/:id", appointmentHandler.GetAppointmentByID)
+			appointments.PUT("/:id", appointmentHandler.UpdateAppointment)
+			appointments.DELETE("/:id", appointmentHandler.DeleteAppointment)
+		}
+
+		regimen := api.Group("/regimen")
+		regimen.Use(middleware.Auth(userRepo))
+		{
+			regimen.GET("", regimenHandler.GetRegimen)
+			regimen.POST("", regimenHandler.RegisterRegimen)
+			regimen.PUT("", regimenHandler.UpdateRegimen)
+			regimen.DELETE("", regimenHandler.DeleteRegimen)
 		}
 	}
 
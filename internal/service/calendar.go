@@ -0,0 +1,79 @@
+package service
+
+import (
+	"okusuri-backend/internal/dto"
+	"okusuri-backend/internal/model"
+	"time"
+)
+
+// GetMedicationCalendar は指定月の日ごとの服薬状態を返す（monthがゼロ値の場合は今月）
+//
+// 休薬期間は服薬ステータスと同じレジメンの計算ロジックで判定する。
+func (s *MedicationService) GetMedicationCalendar(userID string, month time.Time) (*dto.MedicationCalendarResponse, error) {
+	now := s.clock.Now()
+	history, err := s.loadMedicationHistory(userID, now)
+	if err != nil {
+		return nil, err
+	}
+	now = now.In(history.loc)
+
+	if month.IsZero() {
+		month = now
+	}
+	monthStart := time.Date(month.Year(), month.Month(), 1, 0, 0, 0, 0, history.loc)
+	monthEnd := monthStart.AddDate(0, 1, -1)
+
+	// 休薬期間はレジメンに従う薬の服用記録から判定する
+	regimenLogs := takenLogs(filterRegimenLogs(history.logs, history.medications))
+	rests := s.strategyFor(history.regimen).RestPeriods(regimenLogs, history.regimen, monthEnd, now)
+
+	return &dto.MedicationCalendarResponse{
+		Month: monthStart.Format("2006-01"),
+		Days:  buildCalendarDays(history.logs, rests, monthStart),
+	}, nil
+}
+
+// buildCalendarDays はログと休薬期間から月の各日の状態を作成する
+func buildCalendarDays(logs []model.MedicationLog, rests []restPeriod, monthStart time.Time) []dto.CalendarDay {
+	dayIndex := make(map[string]int)
+	var days []dto.CalendarDay
+	for day := monthStart; day.Month() == monthStart.Month(); day = day.AddDate(0, 0, 1) {
+		entry := dto.CalendarDay{Date: day.Format("2006-01-02"), BleedingLevel: model.BleedingLevelNone}
+		for _, rest := range rests {
+			if !day.Before(rest.Start) && !day.After(rest.End) {
+				entry.IsRestPeriod = true
+				break
+			}
+		}
+		dayIndex[entry.Date] = len(days)
+		days = append(days, entry)
+	}
+
+	for _, log := range logs {
+		i, ok := dayIndex[log.CreatedAt.Format("2006-01-02")]
+		if !ok {
+			continue
+		}
+		if log.IsMissed {
+			days[i].Missed = true
+			continue
+		}
+		days[i].Taken = true
+		days[i].LogCount++
+		if log.HasBleeding {
+			days[i].HasBleeding = true
+		}
+		if model.BleedingLevelRank(log.BleedingLevel) > model.BleedingLevelRank(days[i].BleedingLevel) {
+			days[i].BleedingLevel = log.BleedingLevel
+		}
+	}
+
+	// 服用記録がある日は飲み忘れとして扱わない
+	for i := range days {
+		if days[i].Taken {
+			days[i].Missed = false
+		}
+	}
+
+	return days
+}
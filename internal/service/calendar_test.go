@@ -0,0 +1,78 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"okusuri-backend/internal/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildCalendarDays(t *testing.T) {
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	monthStart := time.Date(2025, 2, 1, 0, 0, 0, 0, tokyo)
+
+	t.Run("月の日数分の状態が返される", func(t *testing.T) {
+		days := buildCalendarDays(nil, nil, monthStart)
+		assert.Len(t, days, 28)
+		assert.Equal(t, "2025-02-01", days[0].Date)
+		assert.Equal(t, "2025-02-28", days[27].Date)
+	})
+
+	t.Run("服用・飲み忘れ・出血・休薬期間が日ごとに反映される", func(t *testing.T) {
+		logs := []model.MedicationLog{
+			{CreatedAt: time.Date(2025, 2, 3, 21, 0, 0, 0, tokyo), HasBleeding: true},
+			{CreatedAt: time.Date(2025, 2, 3, 8, 0, 0, 0, tokyo)},
+			{CreatedAt: time.Date(2025, 2, 2, 23, 59, 59, 0, tokyo), IsMissed: true},
+			{CreatedAt: time.Date(2025, 1, 31, 8, 0, 0, 0, tokyo)},
+		}
+		rests := []restPeriod{{
+			Start: time.Date(2025, 1, 29, 0, 0, 0, 0, tokyo),
+			End:   time.Date(2025, 2, 2, 0, 0, 0, 0, tokyo),
+		}}
+
+		days := buildCalendarDays(logs, rests, monthStart)
+
+		assert.True(t, days[0].IsRestPeriod)
+		assert.True(t, days[1].IsRestPeriod)
+		assert.True(t, days[1].Missed)
+		assert.False(t, days[1].Taken)
+
+		assert.False(t, days[2].IsRestPeriod)
+		assert.True(t, days[2].Taken)
+		assert.True(t, days[2].HasBleeding)
+		assert.Equal(t, 2, days[2].LogCount)
+	})
+
+	t.Run("服用記録がある日は飲み忘れとしない", func(t *testing.T) {
+		logs := []model.MedicationLog{
+			{CreatedAt: time.Date(2025, 2, 5, 23, 59, 59, 0, tokyo), IsMissed: true},
+			{CreatedAt: time.Date(2025, 2, 5, 9, 0, 0, 0, tokyo)},
+		}
+
+		days := buildCalendarDays(logs, nil, monthStart)
+		assert.True(t, days[4].Taken)
+		assert.False(t, days[4].Missed)
+	})
+}
+
+func TestCyclicStrategy_RestPeriods(t *testing.T) {
+	service := &MedicationService{}
+	packStart := model.NewDate(2025, 6, 1)
+	regimen := model.NewDefaultRegimen("test-user")
+	regimen.Type = model.RegimenTypeCyclic
+	regimen.PackActiveDays = 21
+	regimen.PackBreakDays = 7
+	regimen.PackStartDate = &packStart
+
+	t.Run("シートごとの偽薬期間が返される", func(t *testing.T) {
+		now := time.Date(2025, 6, 10, 12, 0, 0, 0, time.UTC)
+		rests := service.strategyFor(regimen).RestPeriods(nil, regimen, time.Date(2025, 7, 31, 0, 0, 0, 0, time.UTC), now)
+
+		assert.Len(t, rests, 2)
+		assert.Equal(t, time.Date(2025, 6, 22, 0, 0, 0, 0, time.UTC), rests[0].Start)
+		assert.Equal(t, time.Date(2025, 6, 28, 0, 0, 0, 0, time.UTC), rests[0].End)
+		assert.Equal(t, time.Date(2025, 7, 20, 0, 0, 0, 0, time.UTC), rests[1].Start)
+	})
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"okusuri-backend/internal/dto"
+	"sort"
+	"time"
+)
+
+// cyclePhase は服薬履歴から再構成した服用期間または休薬期間
+type cyclePhase struct {
+	phase        string
+	start        time.Time
+	end          time.Time // 終了日を含む
+	bleedingDays int
+	forced       bool
+}
+
+// GetCycleHistory は服薬履歴全体から過去の服用期間と休薬期間を再構成する
+func (s *MedicationService) GetCycleHistory(userID string) (*dto.CycleHistoryResponse, error) {
+	now := s.clock.Now()
+	history, err := s.loadMedicationHistory(userID, now)
+	if err != nil {
+		return nil, err
+	}
+	now = now.In(history.loc)
+
+	// 休薬期間はレジメンに従う薬の服用記録から判定する
+	regimenLogs := takenLogs(filterRegimenLogs(history.logs, history.medications))
+	rests := s.strategyFor(history.regimen).RestPeriods(regimenLogs, history.regimen, now, now)
+	phases := buildCyclePhases(s.groupLogsByDay(regimenLogs), rests)
+
+	today := startOfDay(now)
+	response := &dto.CycleHistoryResponse{Phases: make([]dto.CyclePhase, 0, len(phases))}
+	for _, phase := range phases {
+		// 服用期間は昨日まで続いていれば進行中とする（連続服用日数と同じ扱い）
+		ongoing := !today.After(phase.end) ||
+			(phase.phase == dto.PhaseActive && daysBetween(phase.end, today) == 1)
+		response.Phases = append(response.Phases, dto.CyclePhase{
+			Phase:        phase.phase,
+			StartDate:    phase.start.Format("2006-01-02"),
+			EndDate:      phase.end.Format("2006-01-02"),
+			LengthDays:   daysBetween(phase.start, phase.end) + 1,
+			BleedingDays: phase.bleedingDays,
+			Forced:       phase.forced,
+			Ongoing:      ongoing && !today.Before(phase.start),
+		})
+	}
+
+	return response, nil
+}
+
+// buildCyclePhases は日ごとの服用記録と休薬期間から、服用期間と休薬期間を古い順に並べる
+//
+// 服用期間は休薬期間外で連続して服用した日のまとまりとし、服用が1日でも途切れた場合は別の期間とする。
+func buildCyclePhases(days []dayLog, rests []restPeriod) []cyclePhase {
+	restIndexAt := func(date time.Time) int {
+		for i, rest := range rests {
+			if !date.Before(rest.Start) && !date.After(rest.End) {
+				return i
+			}
+		}
+		return -1
+	}
+
+	phases := make([]cyclePhase, 0, len(rests))
+	restPhases := make([]cyclePhase, len(rests))
+	for i, rest := range rests {
+		restPhases[i] = cyclePhase{phase: dto.PhaseRest, start: rest.Start, end: rest.End, forced: rest.Forced}
+	}
+
+	var active *cyclePhase
+	closeActive := func() {
+		if active != nil {
+			phases = append(phases, *active)
+			active = nil
+		}
+	}
+
+	for _, day := range days {
+		if i := restIndexAt(day.date); i >= 0 {
+			closeActive()
+			if day.hasBleeding {
+				restPhases[i].bleedingDays++
+			}
+			continue
+		}
+
+		if active == nil || daysBetween(active.end, day.date) != 1 {
+			closeActive()
+			active = &cyclePhase{phase: dto.PhaseActive, start: day.date}
+		}
+		active.end = day.date
+		if day.hasBleeding {
+			active.bleedingDays++
+		}
+	}
+	closeActive()
+
+	phases = append(phases, restPhases...)
+	sort.SliceStable(phases, func(i, j int) bool {
+		return phases[i].start.Before(phases[j].start)
+	})
+	return phases
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"okusuri-backend/internal/dto"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildCyclePhases(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2025, 3, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	t.Run("休薬期間を挟んで服用期間と休薬期間が古い順に並ぶ", func(t *testing.T) {
+		var days []dayLog
+		for d := 1; d <= 10; d++ {
+			days = append(days, dayLog{date: day(d), hasBleeding: d >= 8})
+		}
+		for d := 13; d <= 20; d++ {
+			days = append(days, dayLog{date: day(d)})
+		}
+		rests := []restPeriod{{Start: day(8), End: day(12)}}
+
+		phases := buildCyclePhases(days, rests)
+
+		assert.Len(t, phases, 3)
+		assert.Equal(t, dto.PhaseActive, phases[0].phase)
+		assert.Equal(t, day(1), phases[0].start)
+		assert.Equal(t, day(7), phases[0].end)
+		assert.Equal(t, 0, phases[0].bleedingDays)
+
+		assert.Equal(t, dto.PhaseRest, phases[1].phase)
+		assert.Equal(t, day(8), phases[1].start)
+		assert.Equal(t, 3, phases[1].bleedingDays)
+
+		assert.Equal(t, dto.PhaseActive, phases[2].phase)
+		assert.Equal(t, day(13), phases[2].start)
+		assert.Equal(t, day(20), phases[2].end)
+	})
+
+	t.Run("服用が途切れた場合は別の服用期間になる", func(t *testing.T) {
+		days := []dayLog{
+			{date: day(1)}, {date: day(2)},
+			{date: day(4)}, {date: day(5), hasBleeding: true},
+		}
+
+		phases := buildCyclePhases(days, nil)
+
+		assert.Len(t, phases, 2)
+		assert.Equal(t, day(2), phases[0].end)
+		assert.Equal(t, day(4), phases[1].start)
+		assert.Equal(t, 1, phases[1].bleedingDays)
+	})
+
+	t.Run("記録のない強制休薬も休薬期間として含まれる", func(t *testing.T) {
+		days := []dayLog{{date: day(1)}, {date: day(2)}}
+		rests := []restPeriod{{Start: day(3), End: day(7), Forced: true}}
+
+		phases := buildCyclePhases(days, rests)
+
+		assert.Len(t, phases, 2)
+		assert.True(t, phases[1].forced)
+		assert.Equal(t, day(7), phases[1].end)
+	})
+}
@@ -0,0 +1,285 @@
+package service
+
+import (
+	"math"
+	"okusuri-backend/internal/dto"
+	"okusuri-backend/internal/model"
+	"time"
+)
+
+// guidanceRule は飲み忘れ・服用遅れ時の対処ルール
+type guidanceRule struct {
+	pillTypes               []string // 対象のピルの種類（空の場合は全て）
+	minHoursLate            float64  // 予定時刻からの遅れがこの時間以上の場合に適用
+	minMissedDays           int      // 連続した飲み忘れがこの日数以上の場合に適用
+	level                   string
+	message                 string
+	actions                 []string
+	pendingMessage          string   // まだ服用していない場合のメッセージ（空の場合はmessage）
+	pendingActions          []string // まだ服用していない場合の対処（空の場合はactions）
+	backupContraceptionDays int
+}
+
+// matches はルールが状況に当てはまるかどうかを判定する
+func (r guidanceRule) matches(pillType string, hoursLate float64, missedDays int) bool {
+	if len(r.pillTypes) > 0 {
+		matched := false
+		for _, t := range r.pillTypes {
+			if t == pillType {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if r.minMissedDays > 0 {
+		return missedDays >= r.minMissedDays
+	}
+	return hoursLate >= r.minHoursLate
+}
+
+// guidanceRules は飲み忘れ・服用遅れ時の対処ルール（上から順に評価し、最初に当てはまったものを使用）
+// 連続した飲み忘れの日数によるルールを、服用時間の遅れによるルールより先に評価する
+var guidanceRules = []guidanceRule{
+	{
+		minMissedDays: 2,
+		level:         dto.GuidanceLevelCritical,
+		message:       "2日以上続けて飲み忘れています。避妊効果が低下している可能性があります。",
+		actions: []string{
+			"直近の飲み忘れた1錠をすぐに服用し、それ以外の飲み忘れた錠剤は服用しないでください",
+			"次の錠剤はいつも通りの時間に服用してください",
+			"7日間は他の避妊法を併用してください",
+			"飲み忘れの前後に性交渉があった場合は処方医に相談してください",
+		},
+		backupContraceptionDays: 7,
+	},
+	{
+		// 黄体ホルモンのみのミニピルは服用時間の遅れの許容範囲が短い
+		pillTypes:    []string{model.PillTypeProgestinOnly},
+		minHoursLate: 3,
+		level:        dto.GuidanceLevelCritical,
+		message:      "予定時刻から3時間以上遅れています。避妊効果が低下している可能性があります。",
+		actions: []string{
+			"気づいた時点ですぐに1錠服用してください",
+			"次の錠剤はいつも通りの時間に服用してください",
+			"48時間は他の避妊法を併用してください",
+		},
+		backupContraceptionDays: 2,
+	},
+	{
+		minHoursLate: 48,
+		level:        dto.GuidanceLevelCritical,
+		message:      "予定時刻から48時間以上遅れています。避妊効果が低下している可能性があります。",
+		actions: []string{
+			"直近の飲み忘れた1錠をすぐに服用し、それ以外の飲み忘れた錠剤は服用しないでください",
+			"次の錠剤はいつも通りの時間に服用してください",
+			"7日間は他の避妊法を併用してください",
+		},
+		backupContraceptionDays: 7,
+	},
+	{
+		minHoursLate: 24,
+		level:        dto.GuidanceLevelWarning,
+		message:      "1錠飲み忘れています。",
+		actions: []string{
+			"飲み忘れた1錠をすぐに服用してください（今日の分と2錠同時になっても構いません）",
+			"次の錠剤はいつも通りの時間に服用してください",
+		},
+	},
+	{
+		minHoursLate: 1,
+		level:        dto.GuidanceLevelInfo,
+		message:      "予定時刻より遅れて服用しています。",
+		actions: []string{
+			"次の錠剤はいつも通りの時間に服用してください",
+		},
+		pendingMessage: "予定時刻を過ぎています。まだ服用していません。",
+		pendingActions: []string{
+			"気づいた時点ですぐに1錠服用してください",
+			"次の錠剤はいつも通りの時間に服用してください",
+		},
+	},
+}
+
+// EvaluateGuidance はピルの種類と遅れの状況から対処ガイダンスを返す（対処が不要な場合はnil）
+func EvaluateGuidance(pillType string, hoursLate float64, missedDays int) *dto.GuidanceResponse {
+	return evaluateGuidance(pillType, hoursLate, missedDays, false)
+}
+
+// evaluateGuidance は対処ガイダンスを返す（pendingがtrueの場合はまだ服用していない場合の案内を使う）
+func evaluateGuidance(pillType string, hoursLate float64, missedDays int, pending bool) *dto.GuidanceResponse {
+	for _, rule := range guidanceRules {
+		if !rule.matches(pillType, hoursLate, missedDays) {
+			continue
+		}
+		message, actions := rule.message, rule.actions
+		if pending && rule.pendingMessage != "" {
+			message = rule.pendingMessage
+		}
+		if pending && len(rule.pendingActions) > 0 {
+			actions = rule.pendingActions
+		}
+		return &dto.GuidanceResponse{
+			Level:                   rule.level,
+			HoursLate:               math.Round(hoursLate*10) / 10,
+			MissedDays:              missedDays,
+			Message:                 message,
+			Actions:                 actions,
+			BackupContraceptionDays: rule.backupContraceptionDays,
+		}
+	}
+	return nil
+}
+
+// GetLogGuidance は登録した服薬ログに対する対処ガイダンスを返す
+func (s *MedicationService) GetLogGuidance(userID string, log model.MedicationLog) (*dto.GuidanceResponse, error) {
+	inputs, err := s.guidanceInputs(userID, log.MedicationID)
+	if err != nil || inputs == nil {
+		return nil, err
+	}
+
+	// 登録したログより前の連続した飲み忘れ日数
+	day := startOfDay(log.CreatedAt.In(inputs.loc))
+	missedDays := countMissedDaysBefore(inputs.logs, day)
+
+	// 飲み忘れた日数分と、予定時刻からの遅れを合計する
+	hoursLate := float64(missedDays * 24)
+	if log.DelayMinutes > 0 {
+		hoursLate += float64(log.DelayMinutes) / 60
+	}
+
+	return EvaluateGuidance(inputs.pillType, hoursLate, missedDays), nil
+}
+
+// GetCurrentGuidance は現在の服用状況に対する対処ガイダンスを返す
+func (s *MedicationService) GetCurrentGuidance(userID string, medicationID *uint) (*dto.GuidanceResponse, error) {
+	inputs, err := s.guidanceInputs(userID, medicationID)
+	if err != nil || inputs == nil {
+		return nil, err
+	}
+	logs, loc := inputs.logs, inputs.loc
+
+	// 休薬期間中は服用しないのが正しいため対処不要
+	status, err := s.GetMedicationStatus(userID)
+	if err != nil {
+		return nil, err
+	}
+	if isRestPeriodFor(status, medicationID) {
+		return nil, nil
+	}
+
+	now := s.clock.Now().In(loc)
+	today := startOfDay(now)
+
+	// 今日既に服用している場合は対処不要
+	for _, log := range takenLogs(logs) {
+		if startOfDay(log.CreatedAt).Equal(today) {
+			return nil, nil
+		}
+	}
+
+	missedDays := countMissedDaysBefore(logs, today)
+	hoursLate := float64(missedDays * 24)
+
+	// 予定時刻のある薬は今日の予定時刻からの経過時間を加える
+	if medicationID != nil {
+		medication, err := s.medicationRepo.GetMedicationByID(userID, *medicationID)
+		if err != nil {
+			return nil, err
+		}
+		if scheduled, parseErr := time.Parse("15:04", medication.ScheduledTime); parseErr == nil {
+			scheduledAt := time.Date(today.Year(), today.Month(), today.Day(),
+				scheduled.Hour(), scheduled.Minute(), 0, 0, loc)
+			if now.After(scheduledAt) {
+				hoursLate += now.Sub(scheduledAt).Hours()
+			}
+		}
+	}
+
+	return evaluateGuidance(inputs.pillType, hoursLate, missedDays, true), nil
+}
+
+// isRestPeriodFor は対象の薬（薬の指定がない場合はレジメン）が休薬期間中かどうかを判定する
+func isRestPeriodFor(status *dto.MedicationStatusResponse, medicationID *uint) bool {
+	if medicationID == nil {
+		return status.IsRestPeriod
+	}
+	for _, medicationStatus := range status.Medications {
+		if sameMedication(medicationStatus.MedicationID, medicationID) {
+			return medicationStatus.IsRestPeriod
+		}
+	}
+	return status.IsRestPeriod
+}
+
+// guidanceContext はガイダンスの評価に必要なピルの種類と対象のログ
+type guidanceContext struct {
+	pillType string
+	logs     []model.MedicationLog
+	loc      *time.Location
+}
+
+// guidanceInputs はガイダンスの評価に必要なピルの種類と対象のログを取得する
+// 飲み忘れ時の対処はピルのものであるため、レジメンに従わない薬（毎日服用するサプリメントなど）の場合はnilを返す
+func (s *MedicationService) guidanceInputs(userID string, medicationID *uint) (*guidanceContext, error) {
+	if medicationID != nil {
+		medication, err := s.medicationRepo.GetMedicationByID(userID, *medicationID)
+		if err != nil {
+			return nil, err
+		}
+		if medication.Schedule != model.MedicationScheduleRegimen {
+			return nil, nil
+		}
+	}
+
+	regimen, err := s.GetRegimen(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	loc, err := s.GetLocation(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	allLogs, err := s.medicationRepo.GetLogsByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 対象の薬のログのみを使用する
+	var logs []model.MedicationLog
+	for _, log := range localizeLogs(allLogs, loc) {
+		if sameMedication(log.MedicationID, medicationID) {
+			logs = append(logs, log)
+		}
+	}
+
+	pillType := regimen.PillType
+	if pillType == "" {
+		pillType = model.DefaultPillType
+	}
+
+	return &guidanceContext{pillType: pillType, logs: logs, loc: loc}, nil
+}
+
+// countMissedDaysBefore は指定日の前日から遡って連続した飲み忘れの日数を数える
+func countMissedDaysBefore(logs []model.MedicationLog, day time.Time) int {
+	missedDays := 0
+	for {
+		if !hasMissedOn(logs, day.AddDate(0, 0, -(missedDays+1))) {
+			return missedDays
+		}
+		missedDays++
+	}
+}
+
+// sameMedication は2つの薬IDが同じ薬を指すかどうかを判定する（どちらも未指定の場合も同じとみなす）
+func sameMedication(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"okusuri-backend/internal/dto"
+	"okusuri-backend/internal/model"
+	"okusuri-backend/internal/repository"
+	"okusuri-backend/internal/testutil"
+	"okusuri-backend/pkg/clock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluateGuidance(t *testing.T) {
+	t.Run("遅れがない場合はガイダンスなし", func(t *testing.T) {
+		assert.Nil(t, EvaluateGuidance(model.PillTypeCombined, 0.5, 0))
+	})
+
+	t.Run("数時間の遅れはいつも通りの服用を案内する", func(t *testing.T) {
+		guidance := EvaluateGuidance(model.PillTypeCombined, 5, 0)
+		assert.Equal(t, dto.GuidanceLevelInfo, guidance.Level)
+		assert.Equal(t, 0, guidance.BackupContraceptionDays)
+	})
+
+	t.Run("まだ服用していない場合は服用を促す案内になる", func(t *testing.T) {
+		guidance := evaluateGuidance(model.PillTypeCombined, 5, 0, true)
+		assert.Equal(t, "予定時刻を過ぎています。まだ服用していません。", guidance.Message)
+		assert.Equal(t, "気づいた時点ですぐに1錠服用してください", guidance.Actions[0])
+	})
+
+	t.Run("ミニピルは3時間以上の遅れで他の避妊法の併用を案内する", func(t *testing.T) {
+		guidance := EvaluateGuidance(model.PillTypeProgestinOnly, 4, 0)
+		assert.Equal(t, dto.GuidanceLevelCritical, guidance.Level)
+		assert.Equal(t, 2, guidance.BackupContraceptionDays)
+	})
+
+	t.Run("混合型のピルは3時間の遅れでは他の避妊法の併用を案内しない", func(t *testing.T) {
+		guidance := EvaluateGuidance(model.PillTypeCombined, 4, 0)
+		assert.Equal(t, dto.GuidanceLevelInfo, guidance.Level)
+		assert.Equal(t, 0, guidance.BackupContraceptionDays)
+	})
+
+	t.Run("1日の飲み忘れは警告", func(t *testing.T) {
+		guidance := EvaluateGuidance(model.PillTypeCombined, 26, 1)
+		assert.Equal(t, dto.GuidanceLevelWarning, guidance.Level)
+		assert.Equal(t, 1, guidance.MissedDays)
+	})
+
+	t.Run("2日以上の飲み忘れは7日間の他の避妊法の併用を案内する", func(t *testing.T) {
+		guidance := EvaluateGuidance(model.PillTypeCombined, 48, 2)
+		assert.Equal(t, dto.GuidanceLevelCritical, guidance.Level)
+		assert.Equal(t, 7, guidance.BackupContraceptionDays)
+	})
+
+	t.Run("ミニピルでも2日以上の飲み忘れは7日間の他の避妊法の併用を案内する", func(t *testing.T) {
+		guidance := EvaluateGuidance(model.PillTypeProgestinOnly, 49, 2)
+		assert.Equal(t, dto.GuidanceLevelCritical, guidance.Level)
+		assert.Equal(t, 7, guidance.BackupContraceptionDays)
+	})
+}
+
+func TestMedicationService_GetLogGuidance(t *testing.T) {
+	service := NewMedicationService(repository.NewMedicationRepository(), repository.NewRegimenRepository(),
+		repository.NewUserRepository(), clock.New())
+	ironID := uint(2)
+
+	t.Run("毎日服用する薬（サプリメントなど）にはガイダンスを返さない", func(t *testing.T) {
+		db := testutil.NewFakeDB(t)
+		db.OnQuery(`FROM "medications"`, []string{"id", "user_id", "schedule"},
+			[]driver.Value{int64(ironID), "test-user", model.MedicationScheduleDaily})
+
+		log := model.MedicationLog{CreatedAt: time.Now(), MedicationID: &ironID, DelayMinutes: 5 * 60}
+		guidance, err := service.GetLogGuidance("test-user", log)
+		require.NoError(t, err)
+		assert.Nil(t, guidance)
+		assert.False(t, db.Executed(`FROM "medication_logs"`))
+	})
+}
+
+func TestIsRestPeriodFor(t *testing.T) {
+	pill, iron := uint(1), uint(2)
+	status := &dto.MedicationStatusResponse{
+		IsRestPeriod: true,
+		Medications: []dto.MedicationStatusResponse{
+			{MedicationID: &pill, IsRestPeriod: true},
+			{MedicationID: &iron, IsRestPeriod: false},
+		},
+	}
+
+	t.Run("薬の指定がない場合はレジメンの休薬期間に従う", func(t *testing.T) {
+		assert.True(t, isRestPeriodFor(status, nil))
+	})
+
+	t.Run("薬ごとの休薬期間に従う", func(t *testing.T) {
+		assert.True(t, isRestPeriodFor(status, &pill))
+		assert.False(t, isRestPeriodFor(status, &iron))
+	})
+}
+
+func TestCountMissedDaysBefore(t *testing.T) {
+	today := time.Date(2025, 6, 10, 0, 0, 0, 0, time.UTC)
+	missed := func(daysAgo int) model.MedicationLog {
+		return model.MedicationLog{CreatedAt: today.AddDate(0, 0, -daysAgo).Add(23 * time.Hour), IsMissed: true}
+	}
+
+	t.Run("前日から連続した飲み忘れを数える", func(t *testing.T) {
+		logs := []model.MedicationLog{missed(1), missed(2), {CreatedAt: today.AddDate(0, 0, -3)}, missed(4)}
+		assert.Equal(t, 2, countMissedDaysBefore(logs, today))
+	})
+
+	t.Run("前日に服用していれば0", func(t *testing.T) {
+		logs := []model.MedicationLog{{CreatedAt: today.AddDate(0, 0, -1)}, missed(2)}
+		assert.Equal(t, 0, countMissedDaysBefore(logs, today))
+	})
+}
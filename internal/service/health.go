@@ -0,0 +1,188 @@
+package service
+
+import (
+	"okusuri-backend/internal/dto"
+	"okusuri-backend/internal/model"
+	"okusuri-backend/internal/repository"
+	"okusuri-backend/pkg/clock"
+	"time"
+)
+
+// healthTrendPeriodDays は体調の傾向を比較する期間の日数
+const healthTrendPeriodDays = 30
+
+// healthMetrics は傾向を計算する記録の種類
+var healthMetrics = []string{
+	dto.HealthMetricWeight,
+	dto.HealthMetricSystolicBP,
+	dto.HealthMetricDiastolicBP,
+	dto.HealthMetricMood,
+}
+
+// HealthService は体調の記録を扱うサービス
+type HealthService struct {
+	healthRepo     *repository.HealthRepository
+	medicationRepo *repository.MedicationRepository
+	clock          clock.Clock
+}
+
+func NewHealthService(
+	healthRepo *repository.HealthRepository,
+	medicationRepo *repository.MedicationRepository,
+	clk clock.Clock,
+) *HealthService {
+	return &HealthService{
+		healthRepo:     healthRepo,
+		medicationRepo: medicationRepo,
+		clock:          clk,
+	}
+}
+
+// Now は現在時刻を返す
+func (s *HealthService) Now() time.Time {
+	return s.clock.Now()
+}
+
+// GetTrends は現在の体調の傾向を計算する
+func (s *HealthService) GetTrends(userID string) (*dto.HealthTrendResponse, error) {
+	return s.GetTrendsAt(userID, s.clock.Now())
+}
+
+// GetTrendsAt は指定した日時時点の体調の傾向を計算する
+//
+// 直近30日とその前の30日の平均に加え、薬ごとに服用開始前後30日の平均を比較する。
+func (s *HealthService) GetTrendsAt(userID string, at time.Time) (*dto.HealthTrendResponse, error) {
+	entries, err := s.healthRepo.GetByUserID(userID, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	medications, err := s.medicationRepo.GetMedicationsByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return calculateHealthTrends(entries, medications, at), nil
+}
+
+// calculateHealthTrends は体調の記録から傾向を計算する
+func calculateHealthTrends(entries []model.HealthEntry, medications []model.Medication, now time.Time) *dto.HealthTrendResponse {
+	period := healthTrendPeriodDays * 24 * time.Hour
+	recentFrom := now.Add(-period)
+
+	response := &dto.HealthTrendResponse{
+		PeriodDays:  healthTrendPeriodDays,
+		Metrics:     compareHealthMetrics(entries, recentFrom.Add(-period), recentFrom, now),
+		SideEffects: make(map[string]int),
+	}
+
+	for _, entry := range entries {
+		if inHealthWindow(entry.RecordedAt, recentFrom, now) {
+			for _, sideEffect := range entry.SideEffects {
+				response.SideEffects[sideEffect]++
+			}
+		}
+	}
+
+	// 薬の服用開始前後を比較する（開始後の期間は現在までとする）
+	for _, medication := range medications {
+		startedAt := medication.CreatedAt
+		if startedAt.After(now) {
+			continue
+		}
+		afterEnd := startedAt.Add(period)
+		if afterEnd.After(now) {
+			afterEnd = now
+		}
+
+		metrics := compareHealthMetrics(entries, startedAt.Add(-period), startedAt, afterEnd)
+		if !hasHealthRecords(metrics) {
+			continue
+		}
+		response.Medications = append(response.Medications, dto.MedicationHealthTrend{
+			MedicationID:   medication.ID,
+			MedicationName: medication.Name,
+			StartedAt:      startedAt,
+			Metrics:        metrics,
+		})
+	}
+
+	return response
+}
+
+// compareHealthMetrics は[previousFrom, boundary)と[boundary, recentTo]の期間の平均を記録の種類ごとに比較する
+func compareHealthMetrics(entries []model.HealthEntry, previousFrom, boundary, recentTo time.Time) []dto.HealthMetricTrend {
+	trends := make([]dto.HealthMetricTrend, 0, len(healthMetrics))
+	for _, metric := range healthMetrics {
+		var recentSum, previousSum float64
+		trend := dto.HealthMetricTrend{Metric: metric}
+
+		for _, entry := range entries {
+			value, ok := healthMetricValue(entry, metric)
+			if !ok {
+				continue
+			}
+			switch {
+			case inHealthWindow(entry.RecordedAt, boundary, recentTo):
+				recentSum += value
+				trend.RecentCount++
+			case !entry.RecordedAt.Before(previousFrom) && entry.RecordedAt.Before(boundary):
+				previousSum += value
+				trend.PreviousCount++
+			}
+		}
+
+		if trend.RecentCount > 0 {
+			average := roundTo(recentSum/float64(trend.RecentCount), 1)
+			trend.RecentAverage = &average
+		}
+		if trend.PreviousCount > 0 {
+			average := roundTo(previousSum/float64(trend.PreviousCount), 1)
+			trend.PreviousAverage = &average
+		}
+		if trend.RecentAverage != nil && trend.PreviousAverage != nil {
+			change := roundTo(*trend.RecentAverage-*trend.PreviousAverage, 1)
+			trend.Change = &change
+		}
+		trends = append(trends, trend)
+	}
+	return trends
+}
+
+// healthMetricValue は記録から指定した種類の値を取り出す（記録されていない場合はfalse）
+func healthMetricValue(entry model.HealthEntry, metric string) (float64, bool) {
+	switch metric {
+	case dto.HealthMetricWeight:
+		if entry.WeightKg != nil {
+			return *entry.WeightKg, true
+		}
+	case dto.HealthMetricSystolicBP:
+		if entry.SystolicBP != nil {
+			return float64(*entry.SystolicBP), true
+		}
+	case dto.HealthMetricDiastolicBP:
+		if entry.DiastolicBP != nil {
+			return float64(*entry.DiastolicBP), true
+		}
+	case dto.HealthMetricMood:
+		if entry.MoodScore != nil {
+			return float64(*entry.MoodScore), true
+		}
+	}
+	return 0, false
+}
+
+// inHealthWindow は日時がfrom以上to以下かどうかを判定する
+func inHealthWindow(at, from, to time.Time) bool {
+	return !at.Before(from) && !at.After(to)
+}
+
+// hasHealthRecords はいずれかの期間に記録があるかどうかを判定する
+func hasHealthRecords(trends []dto.HealthMetricTrend) bool {
+	for _, trend := range trends {
+		if trend.RecentCount > 0 || trend.PreviousCount > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"okusuri-backend/internal/dto"
+	"okusuri-backend/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalculateHealthTrends(t *testing.T) {
+	now := time.Date(2025, 6, 30, 12, 0, 0, 0, time.UTC)
+	weight := func(kg float64) *float64 { return &kg }
+	mood := func(score int) *int { return &score }
+
+	entries := []model.HealthEntry{
+		{RecordedAt: now.AddDate(0, 0, -1), WeightKg: weight(52.0), MoodScore: mood(2), SideEffects: model.StringList{"頭痛"}},
+		{RecordedAt: now.AddDate(0, 0, -10), WeightKg: weight(51.0), SideEffects: model.StringList{"頭痛", "吐き気"}},
+		{RecordedAt: now.AddDate(0, 0, -40), WeightKg: weight(50.0), MoodScore: mood(4)},
+		{RecordedAt: now.AddDate(0, 0, -100), WeightKg: weight(45.0)},
+	}
+
+	t.Run("直近30日とその前の30日の平均が比較される", func(t *testing.T) {
+		trends := calculateHealthTrends(entries, nil, now)
+
+		assert.Equal(t, healthTrendPeriodDays, trends.PeriodDays)
+		weightTrend := trends.Metrics[0]
+		assert.Equal(t, dto.HealthMetricWeight, weightTrend.Metric)
+		require.NotNil(t, weightTrend.RecentAverage)
+		assert.Equal(t, 51.5, *weightTrend.RecentAverage)
+		assert.Equal(t, 50.0, *weightTrend.PreviousAverage)
+		assert.Equal(t, 1.5, *weightTrend.Change)
+		assert.Equal(t, 2, weightTrend.RecentCount)
+		assert.Equal(t, 1, weightTrend.PreviousCount)
+
+		moodTrend := trends.Metrics[3]
+		assert.Equal(t, -2.0, *moodTrend.Change)
+
+		bpTrend := trends.Metrics[1]
+		assert.Nil(t, bpTrend.RecentAverage)
+		assert.Nil(t, bpTrend.Change)
+
+		assert.Equal(t, map[string]int{"頭痛": 2, "吐き気": 1}, trends.SideEffects)
+	})
+
+	t.Run("薬の服用開始前後の平均が比較される", func(t *testing.T) {
+		medications := []model.Medication{
+			{ID: 1, Name: "新しいピル", CreatedAt: now.AddDate(0, 0, -20)},
+			{ID: 2, Name: "記録のない期間の薬", CreatedAt: now.AddDate(-2, 0, 0)},
+		}
+
+		trends := calculateHealthTrends(entries, medications, now)
+
+		require.Len(t, trends.Medications, 1)
+		medicationTrend := trends.Medications[0]
+		assert.Equal(t, "新しいピル", medicationTrend.MedicationName)
+		assert.Equal(t, 51.5, *medicationTrend.Metrics[0].RecentAverage)
+		assert.Equal(t, 50.0, *medicationTrend.Metrics[0].PreviousAverage)
+	})
+}
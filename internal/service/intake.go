@@ -0,0 +1,83 @@
+package service
+
+import (
+	"okusuri-backend/internal/dto"
+	"okusuri-backend/internal/model"
+	"time"
+)
+
+// adherencePeriodDays は服用状況を集計する期間（日）
+const adherencePeriodDays = 30
+
+// IntakeClassification は予定時刻に対する服用タイミングの判定結果
+type IntakeClassification struct {
+	Status      string
+	ScheduledAt time.Time
+	Delay       time.Duration
+}
+
+// ClassifyIntake は薬の予定時刻に対する服用タイミングを判定する
+// takenAtはユーザーのタイムゾーンに変換済みであること。予定時刻がない薬の場合はnilを返す
+//
+// 判定には服用日時の時点で迎えている予定時刻（WindowMinutes前からを含む）のうち最も新しいものを用いる。
+//   - 予定時刻の前後WindowMinutes以内の服用は時間通り
+//   - LateLimitMinutesを超えて遅れた場合は飲み忘れ（LateLimitMinutesが0の場合は判定しない）
+//   - それ以外の遅れは遅延（WindowMinutesより早い服用は前回の予定時刻からの遅れとなる）
+func ClassifyIntake(medication model.Medication, takenAt time.Time) *IntakeClassification {
+	scheduled, err := time.Parse("15:04", medication.ScheduledTime)
+	if err != nil {
+		return nil
+	}
+	window := time.Duration(medication.WindowMinutes) * time.Minute
+	lateLimit := time.Duration(medication.LateLimitMinutes) * time.Minute
+
+	// 服用日の予定時刻を迎えていなければ前日の予定時刻に対する服用とする
+	scheduledAt := time.Date(takenAt.Year(), takenAt.Month(), takenAt.Day(),
+		scheduled.Hour(), scheduled.Minute(), 0, 0, takenAt.Location())
+	if takenAt.Add(window).Before(scheduledAt) {
+		scheduledAt = scheduledAt.AddDate(0, 0, -1)
+	}
+
+	delay := takenAt.Sub(scheduledAt)
+
+	status := model.IntakeStatusLate
+	switch {
+	case delay <= window:
+		status = model.IntakeStatusOnTime
+	case lateLimit > 0 && delay > lateLimit:
+		status = model.IntakeStatusMissed
+	}
+
+	return &IntakeClassification{
+		Status:      status,
+		ScheduledAt: scheduledAt,
+		Delay:       delay,
+	}
+}
+
+// calculateAdherence は直近の服用タイミングの判定結果を集計する
+func calculateAdherence(logs []model.MedicationLog, now time.Time) *dto.AdherenceSummary {
+	since := startOfDay(now).AddDate(0, 0, -(adherencePeriodDays - 1))
+	summary := &dto.AdherenceSummary{PeriodDays: adherencePeriodDays}
+
+	for _, log := range logs {
+		if log.CreatedAt.Before(since) {
+			continue
+		}
+		switch log.IntakeStatus {
+		case model.IntakeStatusOnTime:
+			summary.OnTimeCount++
+		case model.IntakeStatusLate:
+			summary.LateCount++
+		case model.IntakeStatusMissed:
+			summary.MissedCount++
+		}
+	}
+
+	total := summary.OnTimeCount + summary.LateCount + summary.MissedCount
+	if total == 0 {
+		return nil
+	}
+	summary.OnTimeRate = float64(summary.OnTimeCount) / float64(total)
+	return summary
+}
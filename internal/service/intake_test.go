@@ -0,0 +1,88 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"okusuri-backend/internal/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyIntake(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	assert.NoError(t, err)
+
+	// 21:00 ±3時間、3時間を超える遅れは飲み忘れ（ミニピルの想定）
+	pop := model.Medication{ScheduledTime: "21:00", WindowMinutes: 180, LateLimitMinutes: 180}
+	// 21:00 ±1時間、飲み忘れ判定なし
+	coc := model.Medication{ScheduledTime: "21:00", WindowMinutes: 60}
+
+	t.Run("予定時刻がない場合は判定しない", func(t *testing.T) {
+		assert.Nil(t, ClassifyIntake(model.Medication{}, time.Now()))
+	})
+
+	t.Run("許容範囲内の服用は時間通り", func(t *testing.T) {
+		intake := ClassifyIntake(pop, time.Date(2025, 6, 10, 23, 30, 0, 0, tokyo))
+		assert.Equal(t, model.IntakeStatusOnTime, intake.Status)
+		assert.Equal(t, 150*time.Minute, intake.Delay)
+		assert.Equal(t, time.Date(2025, 6, 10, 21, 0, 0, 0, tokyo), intake.ScheduledAt)
+	})
+
+	t.Run("許容範囲内で予定時刻より早い服用は時間通り", func(t *testing.T) {
+		intake := ClassifyIntake(coc, time.Date(2025, 6, 10, 20, 30, 0, 0, tokyo))
+		assert.Equal(t, model.IntakeStatusOnTime, intake.Status)
+		assert.Equal(t, time.Date(2025, 6, 10, 21, 0, 0, 0, tokyo), intake.ScheduledAt)
+		assert.Equal(t, -30*time.Minute, intake.Delay)
+	})
+
+	t.Run("許容範囲より早い服用は前日の予定時刻からの遅れとなる", func(t *testing.T) {
+		intake := ClassifyIntake(coc, time.Date(2025, 6, 10, 18, 0, 0, 0, tokyo))
+		assert.Equal(t, model.IntakeStatusLate, intake.Status)
+		assert.Equal(t, time.Date(2025, 6, 9, 21, 0, 0, 0, tokyo), intake.ScheduledAt)
+		assert.Equal(t, 21*time.Hour, intake.Delay)
+	})
+
+	t.Run("翌日の予定時刻に近い大幅な遅れも前日の予定時刻で判定する", func(t *testing.T) {
+		intake := ClassifyIntake(coc, time.Date(2025, 6, 11, 10, 0, 0, 0, tokyo))
+		assert.Equal(t, model.IntakeStatusLate, intake.Status)
+		assert.Equal(t, time.Date(2025, 6, 10, 21, 0, 0, 0, tokyo), intake.ScheduledAt)
+		assert.Equal(t, 13*time.Hour, intake.Delay)
+	})
+
+	t.Run("日付をまたいだ遅れは前日の予定時刻で判定する", func(t *testing.T) {
+		intake := ClassifyIntake(coc, time.Date(2025, 6, 11, 1, 0, 0, 0, tokyo))
+		assert.Equal(t, model.IntakeStatusLate, intake.Status)
+		assert.Equal(t, time.Date(2025, 6, 10, 21, 0, 0, 0, tokyo), intake.ScheduledAt)
+		assert.Equal(t, 4*time.Hour, intake.Delay)
+	})
+
+	t.Run("飲み忘れ扱いの時間を超えた遅れは飲み忘れ", func(t *testing.T) {
+		intake := ClassifyIntake(pop, time.Date(2025, 6, 11, 1, 0, 0, 0, tokyo))
+		assert.Equal(t, model.IntakeStatusMissed, intake.Status)
+	})
+}
+
+func TestCalculateAdherence(t *testing.T) {
+	now := time.Date(2025, 6, 10, 9, 0, 0, 0, time.UTC)
+
+	t.Run("判定済みの記録がない場合はnil", func(t *testing.T) {
+		assert.Nil(t, calculateAdherence(dailyLogs(now, 5), now))
+	})
+
+	t.Run("集計期間内の判定結果を数える", func(t *testing.T) {
+		logs := []model.MedicationLog{
+			{CreatedAt: now, IntakeStatus: model.IntakeStatusOnTime},
+			{CreatedAt: now.AddDate(0, 0, -1), IntakeStatus: model.IntakeStatusOnTime},
+			{CreatedAt: now.AddDate(0, 0, -2), IntakeStatus: model.IntakeStatusLate},
+			{CreatedAt: now.AddDate(0, 0, -3), IntakeStatus: model.IntakeStatusMissed},
+			{CreatedAt: now.AddDate(0, 0, -40), IntakeStatus: model.IntakeStatusMissed},
+		}
+
+		summary := calculateAdherence(logs, now)
+		assert.Equal(t, 2, summary.OnTimeCount)
+		assert.Equal(t, 1, summary.LateCount)
+		assert.Equal(t, 1, summary.MissedCount)
+		assert.InDelta(t, 0.5, summary.OnTimeRate, 0.001)
+	})
+}
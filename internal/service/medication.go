@@ -1,70 +1,274 @@
 package service
 
 import (
+	"errors"
 	"okusuri-backend/internal/dto"
 	"okusuri-backend/internal/model"
 	"okusuri-backend/internal/repository"
+	"okusuri-backend/pkg/clock"
 	"sort"
+	"sync"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type MedicationService struct {
 	medicationRepo *repository.MedicationRepository
+	regimenRepo    *repository.RegimenRepository
+	userRepo       *repository.UserRepository
+	clock          clock.Clock
+
+	// ユーザーごとの統計のキャッシュ（ユーザーID → 集計期間 → 集計結果）
+	statsCache map[string]map[string]cachedStats
+	statsMutex sync.Mutex
 }
 
-func NewMedicationService(medicationRepo *repository.MedicationRepository) *MedicationService {
+func NewMedicationService(
+	medicationRepo *repository.MedicationRepository,
+	regimenRepo *repository.RegimenRepository,
+	userRepo *repository.UserRepository,
+	clk clock.Clock,
+) *MedicationService {
 	return &MedicationService{
 		medicationRepo: medicationRepo,
+		regimenRepo:    regimenRepo,
+		userRepo:       userRepo,
+		clock:          clk,
+		statsCache:     make(map[string]map[string]cachedStats),
+	}
+}
+
+// Now は現在時刻を返す
+func (s *MedicationService) Now() time.Time {
+	return s.clock.Now()
+}
+
+// GetLocation はユーザーのタイムゾーンを取得する
+func (s *MedicationService) GetLocation(userID string) (*time.Location, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
 	}
+	return user.Location(), nil
+}
+
+// GetRegimen はユーザーのレジメンを取得する（未登録の場合はデフォルト値を返す）
+func (s *MedicationService) GetRegimen(userID string) (model.Regimen, error) {
+	regimen, err := s.regimenRepo.GetByUserID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.NewDefaultRegimen(userID), nil
+		}
+		return model.Regimen{}, err
+	}
+	return *regimen, nil
 }
 
 // GetMedicationStatus は現在の服薬ステータスを計算する
 func (s *MedicationService) GetMedicationStatus(userID string) (*dto.MedicationStatusResponse, error) {
+	return s.GetMedicationStatusAt(userID, s.clock.Now())
+}
+
+// medicationHistory は計算に用いるユーザーの服薬履歴とレジメン
+type medicationHistory struct {
+	logs        []model.MedicationLog // 新しい順、ユーザーのタイムゾーンに変換済み
+	regimen     model.Regimen
+	loc         *time.Location
+	medications []model.Medication // 削除済みの薬を含む
+}
+
+// loadMedicationHistory は指定日時までの服薬履歴を計算用に整えて取得する
+func (s *MedicationService) loadMedicationHistory(userID string, at time.Time) (*medicationHistory, error) {
 	// 服薬ログを取得
 	logs, err := s.medicationRepo.GetLogsByUserID(userID)
 	if err != nil {
 		return nil, err
 	}
 
+	// レジメンを取得
+	regimen, err := s.GetRegimen(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// ユーザーのタイムゾーンを取得
+	loc, err := s.GetLocation(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 登録済みの薬を取得（削除済みの薬のログも正しく分類できるよう、削除済みの薬を含める）
+	medications, err := s.medicationRepo.GetMedicationsWithDeleted(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// 指定日時より後のログは計算に含めない
+	logs = filterLogsUntil(logs, at)
+
 	// 日付でソート（新しい順）
 	sort.Slice(logs, func(i, j int) bool {
 		return logs[i].CreatedAt.After(logs[j].CreatedAt)
 	})
 
-	// 現在日時
-	now := time.Now()
+	// 日付の境界はユーザーのタイムゾーンで判定する
+	logs = localizeLogs(logs, loc)
+
+	// 出血の有無はレジメンで数える程度のみとする
+	logs = applyBleedingRule(logs, regimen)
+
+	return &medicationHistory{
+		logs:        logs,
+		regimen:     regimen,
+		loc:         loc,
+		medications: medications,
+	}, nil
+}
 
-	// デフォルトのレスポンス
-	response := &dto.MedicationStatusResponse{
-		CurrentStreak:           0,
-		IsRestPeriod:            false,
-		RestDaysLeft:            0,
-		ConsecutiveBleedingDays: 0,
+// GetMedicationStatusAt は指定した日時時点の服薬ステータスを計算する
+func (s *MedicationService) GetMedicationStatusAt(userID string, at time.Time) (*dto.MedicationStatusResponse, error) {
+	history, err := s.loadMedicationHistory(userID, at)
+	if err != nil {
+		return nil, err
+	}
+	logs, regimen, medications := history.logs, history.regimen, history.medications
+	now := at.In(history.loc)
+
+	// 全体のステータスはレジメンに従う薬（薬の指定がないログを含む）から計算する
+	regimenLogs := filterRegimenLogs(logs, medications)
+	response := s.strategyFor(regimen).CalculateStatus(takenLogs(regimenLogs), regimen, now)
+	response.Adherence = calculateAdherence(logs, now)
+	response.MissedYesterday = hasMissedOn(regimenLogs, startOfDay(now).AddDate(0, 0, -1))
+
+	// 服用中の薬ごとのステータスを計算
+	var activeMedications []model.Medication
+	for _, medication := range medications {
+		if medication.IsActive && !medication.DeletedAt.Valid {
+			activeMedications = append(activeMedications, medication)
+		}
 	}
+	defaultMedication := DefaultMedication(activeMedications)
+	for _, medication := range activeMedications {
+		status := s.calculateMedicationStatus(medication, logs, regimen, now, defaultMedication)
+		response.Medications = append(response.Medications, *status)
+	}
+
+	return response, nil
+}
 
-	// ログが存在しない場合は初期値を返す
-	if len(logs) == 0 {
-		return response, nil
+// calculateMedicationStatus は薬ごとの服薬ステータスを計算する
+// defaultMedicationは薬の指定がないログを割り当てる薬（服用中の薬が1つだけの場合、それ以外はnil）
+func (s *MedicationService) calculateMedicationStatus(
+	medication model.Medication, logs []model.MedicationLog, regimen model.Regimen, now time.Time,
+	defaultMedication *model.Medication,
+) *dto.MedicationStatusResponse {
+	medicationLogs := logsOfMedication(logs, medication.ID, defaultMedication)
+
+	// 毎日服用する薬は休薬期間のない連続投与として扱う
+	if medication.Schedule == model.MedicationScheduleDaily {
+		regimen.Type = model.RegimenTypeContinuous
 	}
 
-	// 休薬期間の判定と連続出血日数の計算
-	isInRestPeriod, restDaysLeft, consecutiveBleedingDays := s.calculateRestPeriodStatus(logs, now)
-	response.IsRestPeriod = isInRestPeriod
-	response.RestDaysLeft = restDaysLeft
-	response.ConsecutiveBleedingDays = consecutiveBleedingDays
+	status := s.strategyFor(regimen).CalculateStatus(takenLogs(medicationLogs), regimen, now)
+	status.Adherence = calculateAdherence(medicationLogs, now)
+	status.MissedYesterday = hasMissedOn(medicationLogs, startOfDay(now).AddDate(0, 0, -1))
+	medicationID := medication.ID
+	status.MedicationID = &medicationID
+	status.MedicationName = medication.Name
+	return status
+}
 
-	// 休薬期間中でなければ、現在の連続服用日数を計算
-	if !isInRestPeriod {
-		response.CurrentStreak = s.calculateCurrentStreak(logs, now)
+// logsOfMedication は指定した薬のログを返す
+// 薬の指定がないログは、服用記録や在庫と同じくdefaultMedicationのログとして扱う
+func logsOfMedication(logs []model.MedicationLog, medicationID uint, defaultMedication *model.Medication) []model.MedicationLog {
+	var medicationLogs []model.MedicationLog
+	for _, log := range logs {
+		switch {
+		case log.MedicationID != nil && *log.MedicationID == medicationID:
+			medicationLogs = append(medicationLogs, log)
+		case log.MedicationID == nil && defaultMedication != nil && defaultMedication.ID == medicationID:
+			medicationLogs = append(medicationLogs, log)
+		}
 	}
+	return medicationLogs
+}
 
-	return response, nil
+// filterRegimenLogs はレジメンに従う薬のログ（薬の指定がないログを含む）のみを返す
+func filterRegimenLogs(logs []model.MedicationLog, medications []model.Medication) []model.MedicationLog {
+	dailyMedicationIDs := make(map[uint]bool)
+	for _, medication := range medications {
+		if medication.Schedule == model.MedicationScheduleDaily {
+			dailyMedicationIDs[medication.ID] = true
+		}
+	}
+	regimenLogs := make([]model.MedicationLog, 0, len(logs))
+	for _, log := range logs {
+		if log.MedicationID == nil || !dailyMedicationIDs[*log.MedicationID] {
+			regimenLogs = append(regimenLogs, log)
+		}
+	}
+	return regimenLogs
 }
 
-// calculateRestPeriodStatus は休薬期間の状態を計算する
-func (s *MedicationService) calculateRestPeriodStatus(logs []model.MedicationLog, now time.Time) (bool, int, int) {
-	const restPeriodDays = 4 // 休薬期間は4日間
+// takenLogs は飲み忘れの自動記録を除いた、実際に服用したログのみを返す
+func takenLogs(logs []model.MedicationLog) []model.MedicationLog {
+	taken := make([]model.MedicationLog, 0, len(logs))
+	for _, log := range logs {
+		if !log.IsMissed {
+			taken = append(taken, log)
+		}
+	}
+	return taken
+}
+
+// hasMissedOn は指定日に飲み忘れの自動記録があるかどうかを判定する
+func hasMissedOn(logs []model.MedicationLog, day time.Time) bool {
+	for _, log := range logs {
+		if log.IsMissed && startOfDay(log.CreatedAt).Equal(day) {
+			return true
+		}
+	}
+	return false
+}
+
+// filterLogsUntil は指定日時以前のログのみを返す
+func filterLogsUntil(logs []model.MedicationLog, at time.Time) []model.MedicationLog {
+	filtered := logs[:0]
+	for _, log := range logs {
+		if !log.CreatedAt.After(at) {
+			filtered = append(filtered, log)
+		}
+	}
+	return filtered
+}
 
+// localizeLogs はログの日時をユーザーのタイムゾーンに変換する
+//
+// 以降の日付ごとの集計（Formatやtime.Date）は日時が持つタイムゾーンで行われるため、
+// 計算の前に必ず変換しておく。
+func localizeLogs(logs []model.MedicationLog, loc *time.Location) []model.MedicationLog {
+	for i := range logs {
+		logs[i].CreatedAt = logs[i].CreatedAt.In(loc)
+	}
+	return logs
+}
+
+// applyBleedingRule はログの出血の有無を、レジメンで連続出血日数に数える程度かどうかに置き換える
+// 出血の程度が記録されていないログ（程度の導入前のデータ）はそのままとする
+func applyBleedingRule(logs []model.MedicationLog, regimen model.Regimen) []model.MedicationLog {
+	for i := range logs {
+		if logs[i].BleedingLevel != "" {
+			logs[i].HasBleeding = regimen.CountsAsBleeding(logs[i].BleedingLevel)
+		}
+	}
+	return logs
+}
+
+// calculateRestPeriodStatus は休薬期間の状態を計算する
+func (s *MedicationService) calculateRestPeriodStatus(
+	logs []model.MedicationLog, regimen model.Regimen, now time.Time,
+) (bool, int, int) {
 	// 日付ごとに整理したログを取得（同じ日の重複を除去）
 	dateLogMap := make(map[string]model.MedicationLog)
 	for _, log := range logs {
@@ -87,7 +291,6 @@ func (s *MedicationService) calculateRestPeriodStatus(logs []model.MedicationLog
 
 	// 連続出血日数を計算
 	consecutiveBleedingDays := 0
-	var consecutiveBleedingDates []time.Time
 	lastDate := time.Time{}
 
 	for _, date := range dates {
@@ -102,19 +305,16 @@ func (s *MedicationService) calculateRestPeriodStatus(logs []model.MedicationLog
 			// 初めての出血日または連続している場合
 			if consecutiveBleedingDays == 0 || lastDate.IsZero() {
 				consecutiveBleedingDays = 1
-				consecutiveBleedingDates = append(consecutiveBleedingDates, currDate)
 			} else {
 				// 日付の差を計算
-				dayDiff := int(lastDate.Sub(currDate).Hours() / 24)
+				dayDiff := daysBetween(currDate, lastDate)
 
 				// 前日からの連続か確認
 				if dayDiff == 1 {
 					consecutiveBleedingDays++
-					consecutiveBleedingDates = append(consecutiveBleedingDates, currDate)
 				} else {
 					// 日付が連続していない場合はリセット
 					consecutiveBleedingDays = 1
-					consecutiveBleedingDates = []time.Time{currDate}
 				}
 			}
 		} else {
@@ -125,64 +325,187 @@ func (s *MedicationService) calculateRestPeriodStatus(logs []model.MedicationLog
 		lastDate = currDate
 	}
 
-	// 連続3日間以上の出血がある場合、休薬期間判定
-	if consecutiveBleedingDays >= 3 && len(consecutiveBleedingDates) >= 3 {
-		// 休薬開始日は連続出血の最初の日
-		restStartDate := consecutiveBleedingDates[len(consecutiveBleedingDates)-1]
-
-		// 休薬終了日は休薬開始日から4日後の終日
-		restEndDate := restStartDate.AddDate(0, 0, restPeriodDays)
-		restEndDate = time.Date(
-			restEndDate.Year(), restEndDate.Month(), restEndDate.Day(),
-			23, 59, 59, 0, restEndDate.Location(),
+	// 休薬期間の判定はレジメンのルールに従って服薬履歴全体から行う
+	timeline := s.buildFlexTimeline(logs, regimen, now)
+	if rest := timeline.restPeriodAt(now); rest != nil {
+		// 休薬終了日の終日まで休薬期間とする
+		restEndDate := time.Date(
+			rest.End.Year(), rest.End.Month(), rest.End.Day(),
+			23, 59, 59, 0, rest.End.Location(),
 		)
 
-		// 現在が休薬期間内かどうか
-		if now.Before(restEndDate) {
-			// 残り日数を計算（日単位で切り上げ）
-			duration := restEndDate.Sub(now)
-			daysLeft := int(duration.Hours() / 24)
-			if duration.Hours() > float64(daysLeft*24) {
-				daysLeft++
-			}
-			return true, daysLeft, consecutiveBleedingDays
+		// 残り日数を計算（日単位で切り上げ）
+		duration := restEndDate.Sub(now)
+		daysLeft := int(duration.Hours() / 24)
+		if duration.Hours() > float64(daysLeft*24) {
+			daysLeft++
 		}
+		return true, daysLeft, consecutiveBleedingDays
 	}
 
 	return false, 0, consecutiveBleedingDays
 }
 
-// calculateCurrentStreak は現在の連続服用日数を計算する
-func (s *MedicationService) calculateCurrentStreak(logs []model.MedicationLog, now time.Time) int {
-	lastRestPeriodEndDate := s.findLastRestPeriodEndDate(logs)
-	uniqueDates := s.extractUniqueDates(logs)
-	return s.countConsecutiveDays(uniqueDates, lastRestPeriodEndDate, now)
+// restPeriod は休薬期間（開始日と終了日を含む）
+type restPeriod struct {
+	Start  time.Time
+	End    time.Time
+	Forced bool // 最大連続服用日数に達したことによる休薬かどうか
 }
 
-// findLastRestPeriodEndDate は最後の休薬期間終了日を探す
-func (s *MedicationService) findLastRestPeriodEndDate(logs []model.MedicationLog) time.Time {
-	consecutiveBleedingCount := 0
-	var bleedingDates []time.Time
+// flexTimeline はフレキシブル投与の服薬履歴を時系列で解析した結果
+type flexTimeline struct {
+	restPeriods []restPeriod
+	activeStart time.Time // 現在の服用期間の開始日（服用が途切れている場合はゼロ値）
+}
 
-	for i, log := range logs {
-		if log.HasBleeding {
-			consecutiveBleedingCount++
-			bleedingDates = append(bleedingDates, log.CreatedAt)
+// restPeriodAt は指定日時を含む休薬期間を返す
+func (t flexTimeline) restPeriodAt(at time.Time) *restPeriod {
+	day := startOfDay(at)
+	for i := len(t.restPeriods) - 1; i >= 0; i-- {
+		rest := t.restPeriods[i]
+		if !day.Before(rest.Start) && !day.After(rest.End) {
+			return &rest
+		}
+	}
+	return nil
+}
+
+// lastRestPeriod は最後の休薬期間を返す
+func (t flexTimeline) lastRestPeriod() *restPeriod {
+	if len(t.restPeriods) == 0 {
+		return nil
+	}
+	return &t.restPeriods[len(t.restPeriods)-1]
+}
+
+// buildFlexTimeline は服薬履歴を古い順にたどり、レジメンに従って休薬期間を再構成する
+//
+// 休薬は以下のいずれかで開始する。
+//   - 連続出血日数が閾値に達し、かつ服用期間が最低連続服用日数以上の場合（出血初日から休薬）
+//   - 服用期間が最大連続服用日数に達した場合（翌日から強制休薬）
+func (s *MedicationService) buildFlexTimeline(
+	logs []model.MedicationLog, regimen model.Regimen, now time.Time,
+) flexTimeline {
+	var timeline flexTimeline
+	var activeStart, prevDay, bleedingStart, restUntil time.Time
+	bleedingDays := 0
+
+	startRest := func(rest restPeriod) {
+		timeline.restPeriods = append(timeline.restPeriods, rest)
+		restUntil = rest.End
+		activeStart = time.Time{}
+		bleedingDays = 0
+	}
+
+	for _, day := range s.groupLogsByDay(logs) {
+		// 最大連続服用日数まで服用を続けた後のログの場合は強制休薬を挟む
+		forced := s.forcedRestStart(activeStart, regimen)
+		if !forced.IsZero() && !day.date.Before(forced) && daysBetween(prevDay, forced) == 1 {
+			startRest(restPeriod{Start: forced, End: forced.AddDate(0, 0, regimen.RestPeriodDays), Forced: true})
+		}
+
+		// 休薬期間中のログは服用期間に含めない
+		if !restUntil.IsZero() && !day.date.After(restUntil) {
+			continue
+		}
 
-			if consecutiveBleedingCount >= 3 {
-				oldestBleedingDate := bleedingDates[len(bleedingDates)-1]
-				return oldestBleedingDate.AddDate(0, 0, 4)
+		// 服用が途切れた場合は新しい服用期間として数え直す
+		if activeStart.IsZero() || daysBetween(prevDay, day.date) != 1 {
+			activeStart = day.date
+			bleedingDays = 0
+		}
+		prevDay = day.date
+
+		if day.hasBleeding {
+			if bleedingDays == 0 {
+				bleedingStart = day.date
 			}
+			bleedingDays++
 		} else {
-			consecutiveBleedingCount = 0
-			bleedingDates = nil
+			bleedingDays = 0
+		}
 
-			if i >= 3 && logs[i-1].HasBleeding && logs[i-2].HasBleeding && logs[i-3].HasBleeding {
-				return log.CreatedAt
-			}
+		activeDays := daysBetween(activeStart, day.date) + 1
+		if bleedingDays >= regimen.BleedingDaysThreshold && activeDays >= regimen.MinActiveDays {
+			startRest(restPeriod{Start: bleedingStart, End: bleedingStart.AddDate(0, 0, regimen.RestPeriodDays)})
 		}
 	}
-	return time.Time{}
+
+	if activeStart.IsZero() {
+		return timeline
+	}
+
+	// 最大連続服用日数まで服用を続けた後は、ログがなくても強制休薬とする
+	today := startOfDay(now)
+	forced := s.forcedRestStart(activeStart, regimen)
+	if !forced.IsZero() && !today.Before(forced) && daysBetween(prevDay, forced) <= 1 {
+		startRest(restPeriod{Start: forced, End: forced.AddDate(0, 0, regimen.RestPeriodDays), Forced: true})
+		return timeline
+	}
+
+	// 昨日までに服用が途切れていなければ服用期間中とする
+	if daysBetween(prevDay, today) <= 1 {
+		timeline.activeStart = activeStart
+	}
+	return timeline
+}
+
+// forcedRestStart は服用期間の開始日から強制休薬が始まる日を返す（制限なしの場合はゼロ値）
+func (s *MedicationService) forcedRestStart(activeStart time.Time, regimen model.Regimen) time.Time {
+	if activeStart.IsZero() || regimen.MaxContinuousDays <= 0 {
+		return time.Time{}
+	}
+	return activeStart.AddDate(0, 0, regimen.MaxContinuousDays)
+}
+
+// dayLog は1日分の服薬記録
+type dayLog struct {
+	date        time.Time
+	hasBleeding bool
+}
+
+// groupLogsByDay はログを日付ごとにまとめて古い順に返す（同じ日は最新のログを使用）
+func (s *MedicationService) groupLogsByDay(logs []model.MedicationLog) []dayLog {
+	dayMap := make(map[string]dayLog)
+	latest := make(map[string]time.Time)
+	for _, log := range logs {
+		dateStr := log.CreatedAt.Format("2006-01-02")
+		if at, exists := latest[dateStr]; exists && !log.CreatedAt.After(at) {
+			continue
+		}
+		latest[dateStr] = log.CreatedAt
+		dayMap[dateStr] = dayLog{date: startOfDay(log.CreatedAt), hasBleeding: log.HasBleeding}
+	}
+
+	days := make([]dayLog, 0, len(dayMap))
+	for _, day := range dayMap {
+		days = append(days, day)
+	}
+	sort.Slice(days, func(i, j int) bool {
+		return days[i].date.Before(days[j].date)
+	})
+	return days
+}
+
+// calculateCurrentStreak は現在の連続服用日数を計算する
+func (s *MedicationService) calculateCurrentStreak(
+	logs []model.MedicationLog, regimen model.Regimen, now time.Time,
+) int {
+	lastRestPeriodEndDate := s.findLastRestPeriodEndDate(logs, regimen, now)
+	uniqueDates := s.extractUniqueDates(logs)
+	return s.countConsecutiveDays(uniqueDates, lastRestPeriodEndDate, now)
+}
+
+// findLastRestPeriodEndDate は最後の休薬期間終了日の翌日（服用再開日）を探す
+func (s *MedicationService) findLastRestPeriodEndDate(
+	logs []model.MedicationLog, regimen model.Regimen, now time.Time,
+) time.Time {
+	rest := s.buildFlexTimeline(logs, regimen, now).lastRestPeriod()
+	if rest == nil {
+		return time.Time{}
+	}
+	return rest.End.AddDate(0, 0, 1)
 }
 
 // extractUniqueDates は重複を除去した日付リストを取得する
@@ -226,7 +549,7 @@ func (s *MedicationService) countConsecutiveDays(dates []time.Time, restEndDate
 			continue
 		}
 
-		dayDiff := int(lastDate.Sub(currDate).Hours() / 24)
+		dayDiff := daysBetween(currDate, lastDate)
 		if dayDiff == 1 {
 			streak++
 			lastDate = currDate
@@ -236,7 +559,3 @@ func (s *MedicationService) countConsecutiveDays(dates []time.Time, restEndDate
 	}
 	return streak
 }
-
-
-
-
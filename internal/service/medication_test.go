@@ -0,0 +1,281 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"okusuri-backend/internal/dto"
+	"okusuri-backend/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// bleedingLogs は指定日から過去に遡って出血ありのログを作成する（新しい順）
+func bleedingLogs(latest time.Time, days int) []model.MedicationLog {
+	var logs []model.MedicationLog
+	for i := 0; i < days; i++ {
+		logs = append(logs, model.MedicationLog{
+			CreatedAt:   latest.AddDate(0, 0, -i),
+			HasBleeding: true,
+		})
+	}
+	return logs
+}
+
+func TestMedicationService_CalculateRestPeriodStatus(t *testing.T) {
+	service := &MedicationService{}
+	now := time.Date(2025, 6, 10, 9, 0, 0, 0, time.UTC)
+
+	t.Run("デフォルトのレジメンでは3日連続の出血で休薬期間になる", func(t *testing.T) {
+		logs := bleedingLogs(now, 3)
+		regimen := model.NewDefaultRegimen("test-user")
+
+		isRest, daysLeft, bleedingDays := service.calculateRestPeriodStatus(logs, regimen, now)
+		assert.True(t, isRest)
+		assert.Equal(t, 3, bleedingDays)
+		assert.Equal(t, 3, daysLeft)
+	})
+
+	t.Run("出血日数の閾値がレジメンに従う", func(t *testing.T) {
+		logs := bleedingLogs(now, 3)
+		regimen := model.NewDefaultRegimen("test-user")
+		regimen.BleedingDaysThreshold = 5
+
+		isRest, _, bleedingDays := service.calculateRestPeriodStatus(logs, regimen, now)
+		assert.False(t, isRest)
+		assert.Equal(t, 3, bleedingDays)
+	})
+
+	t.Run("休薬日数がレジメンに従う", func(t *testing.T) {
+		logs := bleedingLogs(now, 3)
+		regimen := model.NewDefaultRegimen("test-user")
+		regimen.RestPeriodDays = 7
+
+		isRest, daysLeft, _ := service.calculateRestPeriodStatus(logs, regimen, now)
+		assert.True(t, isRest)
+		assert.Equal(t, 6, daysLeft)
+	})
+}
+
+func TestFlexibleStrategy_ContinuousDayLimits(t *testing.T) {
+	service := &MedicationService{}
+	now := time.Date(2025, 6, 10, 9, 0, 0, 0, time.UTC)
+
+	t.Run("最低連続服用日数に満たない場合は出血しても休薬にならない", func(t *testing.T) {
+		// 5日間服用し、直近3日間は出血あり
+		logs := append(bleedingLogs(now, 3), dailyLogs(now.AddDate(0, 0, -3), 2)...)
+		regimen := model.NewDefaultRegimen("test-user")
+		regimen.MinActiveDays = 24
+
+		status := service.strategyFor(regimen).CalculateStatus(logs, regimen, now)
+		assert.False(t, status.IsRestPeriod)
+		assert.False(t, status.RestAllowed)
+		assert.Equal(t, 5, status.CurrentStreak)
+		assert.Equal(t, 3, status.ConsecutiveBleedingDays)
+	})
+
+	t.Run("最低連続服用日数を満たした後の出血で休薬になる", func(t *testing.T) {
+		logs := append(bleedingLogs(now, 3), dailyLogs(now.AddDate(0, 0, -3), 24)...)
+		regimen := model.NewDefaultRegimen("test-user")
+		regimen.MinActiveDays = 24
+
+		status := service.strategyFor(regimen).CalculateStatus(logs, regimen, now)
+		assert.True(t, status.IsRestPeriod)
+		assert.Equal(t, 3, status.RestDaysLeft)
+	})
+
+	t.Run("最大連続服用日数に達すると強制休薬になる", func(t *testing.T) {
+		// 昨日までの10日間服用
+		logs := dailyLogs(now.AddDate(0, 0, -1), 10)
+		regimen := model.NewDefaultRegimen("test-user")
+		regimen.MaxContinuousDays = 10
+
+		status := service.strategyFor(regimen).CalculateStatus(logs, regimen, now)
+		assert.True(t, status.IsRestPeriod)
+		assert.Equal(t, dto.PhaseRest, status.Phase)
+		assert.Equal(t, 1, status.DayInPack)
+		assert.Equal(t, 5, status.RestDaysLeft)
+	})
+
+	t.Run("服用期間中は強制休薬日を返す", func(t *testing.T) {
+		logs := dailyLogs(now, 100)
+		regimen := model.NewDefaultRegimen("test-user")
+		regimen.MaxContinuousDays = 120
+
+		status := service.strategyFor(regimen).CalculateStatus(logs, regimen, now)
+		assert.False(t, status.IsRestPeriod)
+		assert.True(t, status.RestAllowed)
+		assert.Equal(t, 100, status.CurrentStreak)
+		expected := time.Date(2025, 7, 1, 0, 0, 0, 0, time.UTC)
+		assert.Equal(t, expected, *status.ForcedRestDueAt)
+	})
+
+	t.Run("服用が途切れた後は強制休薬にならない", func(t *testing.T) {
+		// 30日前から10日間服用した後、今日から再開
+		logs := append(dailyLogs(now, 1), dailyLogs(now.AddDate(0, 0, -30), 10)...)
+		regimen := model.NewDefaultRegimen("test-user")
+		regimen.MaxContinuousDays = 10
+
+		status := service.strategyFor(regimen).CalculateStatus(logs, regimen, now)
+		assert.False(t, status.IsRestPeriod)
+		assert.Equal(t, 1, status.CurrentStreak)
+	})
+}
+
+func TestLocalizeLogs(t *testing.T) {
+	service := &MedicationService{}
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	assert.NoError(t, err)
+
+	t.Run("日本時間の朝の服用が前日として数えられない", func(t *testing.T) {
+		// 日本時間 6/9 08:00 と 6/10 08:00（UTCではそれぞれ前日の23:00）
+		logs := []model.MedicationLog{
+			{CreatedAt: time.Date(2025, 6, 9, 23, 0, 0, 0, time.UTC)},
+			{CreatedAt: time.Date(2025, 6, 8, 23, 0, 0, 0, time.UTC)},
+		}
+		now := time.Date(2025, 6, 10, 12, 0, 0, 0, tokyo)
+
+		dates := service.extractUniqueDates(localizeLogs(logs, tokyo))
+		assert.Equal(t, 10, dates[0].Day())
+		assert.Equal(t, 9, dates[1].Day())
+		assert.Equal(t, 2, service.countConsecutiveDays(dates, time.Time{}, now))
+	})
+}
+
+func TestDaylightSavingTimeTransition(t *testing.T) {
+	service := &MedicationService{}
+	newYork, err := time.LoadLocation("America/New_York")
+	assert.NoError(t, err)
+
+	// 2025年3月9日にサマータイムが始まり、3/8と3/9の0時の間は23時間になる
+	t.Run("サマータイムの開始をまたいでも連続服用日数が途切れない", func(t *testing.T) {
+		now := time.Date(2025, 3, 12, 12, 0, 0, 0, newYork)
+		var dates []time.Time
+		for day := 12; day >= 8; day-- {
+			dates = append(dates, time.Date(2025, 3, day, 9, 0, 0, 0, newYork))
+		}
+
+		assert.Equal(t, 5, service.countConsecutiveDays(dates, time.Time{}, now))
+	})
+
+	t.Run("サマータイムの開始をまたいでも連続出血日数が途切れない", func(t *testing.T) {
+		now := time.Date(2025, 3, 10, 12, 0, 0, 0, newYork)
+		logs := bleedingLogs(time.Date(2025, 3, 10, 9, 0, 0, 0, newYork), 3)
+		regimen := model.NewDefaultRegimen("test-user")
+
+		_, _, bleedingDays := service.calculateRestPeriodStatus(logs, regimen, now)
+		assert.Equal(t, 3, bleedingDays)
+	})
+}
+
+func TestMedicationService_CalculateMedicationStatus(t *testing.T) {
+	service := &MedicationService{}
+	now := time.Date(2025, 6, 10, 9, 0, 0, 0, time.UTC)
+	regimen := model.NewDefaultRegimen("test-user")
+
+	pillID, ironID := uint(1), uint(2)
+	pill := model.Medication{ID: pillID, Name: "ピル", Schedule: model.MedicationScheduleRegimen}
+	iron := model.Medication{ID: ironID, Name: "鉄剤", Schedule: model.MedicationScheduleDaily}
+
+	logs := bleedingLogs(now, 3)
+	for i := range logs {
+		logs[i].MedicationID = &pillID
+	}
+	logs = append(logs, model.MedicationLog{CreatedAt: now, MedicationID: &ironID})
+
+	t.Run("レジメンに従う薬は出血で休薬期間になる", func(t *testing.T) {
+		status := service.calculateMedicationStatus(pill, logs, regimen, now, nil)
+		assert.Equal(t, pillID, *status.MedicationID)
+		assert.Equal(t, "ピル", status.MedicationName)
+		assert.True(t, status.IsRestPeriod)
+	})
+
+	t.Run("服用中の薬が1つだけの場合は薬の指定がないログもその薬のログとして数える", func(t *testing.T) {
+		status := service.calculateMedicationStatus(pill, bleedingLogs(now, 3), regimen, now, &pill)
+		assert.True(t, status.IsRestPeriod)
+		assert.Equal(t, 3, status.ConsecutiveBleedingDays)
+	})
+
+	t.Run("毎日服用する薬はその薬のログだけで連続服用日数を数える", func(t *testing.T) {
+		status := service.calculateMedicationStatus(iron, logs, regimen, now, nil)
+		assert.Equal(t, ironID, *status.MedicationID)
+		assert.False(t, status.IsRestPeriod)
+		assert.Equal(t, model.RegimenTypeContinuous, status.RegimenType)
+		assert.Equal(t, 1, status.CurrentStreak)
+	})
+}
+
+func TestApplyBleedingRule(t *testing.T) {
+	service := &MedicationService{}
+	now := time.Date(2025, 6, 10, 9, 0, 0, 0, time.UTC)
+
+	// 直近3日間のうち1日が少量の不正出血
+	newLogs := func() []model.MedicationLog {
+		levels := []string{model.BleedingLevelMedium, model.BleedingLevelSpotting, model.BleedingLevelLight}
+		var logs []model.MedicationLog
+		for i, level := range levels {
+			log := model.MedicationLog{CreatedAt: now.AddDate(0, 0, -i)}
+			log.SetBleedingLevel(level)
+			logs = append(logs, log)
+		}
+		return logs
+	}
+
+	t.Run("デフォルトでは不正出血も連続出血日数に数える", func(t *testing.T) {
+		regimen := model.NewDefaultRegimen("test-user")
+		logs := applyBleedingRule(newLogs(), regimen)
+
+		isRest, _, bleedingDays := service.calculateRestPeriodStatus(logs, regimen, now)
+		assert.True(t, isRest)
+		assert.Equal(t, 3, bleedingDays)
+	})
+
+	t.Run("数える程度をlight以上にすると不正出血は除外される", func(t *testing.T) {
+		regimen := model.NewDefaultRegimen("test-user")
+		regimen.MinBleedingLevel = model.BleedingLevelLight
+		logs := applyBleedingRule(newLogs(), regimen)
+
+		isRest, _, bleedingDays := service.calculateRestPeriodStatus(logs, regimen, now)
+		assert.False(t, isRest)
+		assert.Equal(t, 1, bleedingDays)
+	})
+
+	t.Run("出血の程度がないログは出血の有無をそのまま使う", func(t *testing.T) {
+		regimen := model.NewDefaultRegimen("test-user")
+		regimen.MinBleedingLevel = model.BleedingLevelHeavy
+		logs := applyBleedingRule(bleedingLogs(now, 3), regimen)
+
+		for _, log := range logs {
+			assert.True(t, log.HasBleeding)
+		}
+	})
+}
+
+// レジメンに従う薬のログの抽出のテスト
+func TestFilterRegimenLogs(t *testing.T) {
+	pill, vitamin := uint(1), uint(2)
+	now := time.Date(2025, 6, 10, 21, 0, 0, 0, time.UTC)
+	logs := []model.MedicationLog{
+		{ID: 1, CreatedAt: now},
+		{ID: 2, CreatedAt: now, MedicationID: &pill},
+		{ID: 3, CreatedAt: now, MedicationID: &vitamin},
+	}
+
+	t.Run("削除済みの毎日服用する薬のログもレジメンのログから除外される", func(t *testing.T) {
+		medications := []model.Medication{
+			{ID: pill, Schedule: model.MedicationScheduleRegimen},
+			{
+				ID:        vitamin,
+				Schedule:  model.MedicationScheduleDaily,
+				DeletedAt: gorm.DeletedAt{Time: now, Valid: true},
+			},
+		}
+
+		regimenLogs := filterRegimenLogs(logs, medications)
+		assert.Len(t, regimenLogs, 2)
+		for _, log := range regimenLogs {
+			assert.NotEqual(t, uint(3), log.ID)
+		}
+	})
+}
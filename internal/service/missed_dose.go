@@ -0,0 +1,145 @@
+package service
+
+import (
+	"fmt"
+	"okusuri-backend/internal/dto"
+	"okusuri-backend/internal/model"
+	"time"
+)
+
+// missedDoseLookbackDays は飲み忘れを記録する条件となる、直近で服用記録を探す日数
+// アプリの利用をやめたユーザーに飲み忘れが記録され続けないようにする
+const missedDoseLookbackDays = 7
+
+// missedDoseDispatchKind は飲み忘れの記録の実行記録の種類（複数のサーバーで重複して記録しないようにする）
+const missedDoseDispatchKind = "missed_dose"
+
+// missedDoseTarget は飲み忘れを判定する対象（薬ごと、または薬の指定がない従来の記録）
+type missedDoseTarget struct {
+	medicationID *uint
+	logs         []model.MedicationLog
+	isRestPeriod bool
+}
+
+// RecordMissedDoses はユーザーの前日（ユーザーのタイムゾーン）に服用記録がない場合、飲み忘れとして記録する
+// 同じ日に対して何度実行しても（複数のサーバーで実行しても）記録は重複しない。記録した件数を返す
+func (s *MedicationService) RecordMissedDoses(user model.User) (int, error) {
+	loc := user.Location()
+	today := startOfDay(s.clock.Now().In(loc))
+	yesterday := today.AddDate(0, 0, -1)
+	endOfYesterday := today.Add(-time.Second)
+
+	logs, err := s.medicationRepo.GetLogsByUserID(user.ID)
+	if err != nil {
+		return 0, err
+	}
+	logs = localizeLogs(logs, loc)
+
+	medications, err := s.medicationRepo.GetMedicationsByUserID(user.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	// 休薬期間中は服用しないのが正しいため、前日終了時点のステータスで判定する
+	status, err := s.GetMedicationStatusAt(user.ID, endOfYesterday)
+	if err != nil {
+		return 0, err
+	}
+
+	recorded := 0
+	for _, target := range s.missedDoseTargets(logs, medications, status, endOfYesterday) {
+		if !s.isMissedDose(target, yesterday) {
+			continue
+		}
+
+		missedLog := model.MedicationLog{
+			CreatedAt:    endOfYesterday,
+			UserID:       user.ID,
+			MedicationID: target.medicationID,
+			IntakeStatus: model.IntakeStatusMissed,
+			IsMissed:     true,
+		}
+		claimed, err := s.medicationRepo.RegisterLogWithDispatch(
+			user.ID, missedLog, missedDoseDispatchKind, missedDoseSlot(yesterday, target.medicationID))
+		if err != nil {
+			return recorded, err
+		}
+		if claimed {
+			recorded++
+		}
+	}
+
+	return recorded, nil
+}
+
+// missedDoseSlot は飲み忘れの記録の実行記録のキー（日付と薬ごと）を返す
+func missedDoseSlot(day time.Time, medicationID *uint) string {
+	if medicationID == nil {
+		return day.Format("2006-01-02") + "/-"
+	}
+	return fmt.Sprintf("%s/%d", day.Format("2006-01-02"), *medicationID)
+}
+
+// missedDoseTargets は飲み忘れを判定する対象を返す
+// 服用中の薬が登録されている場合は薬ごと、登録されていない場合は薬の指定がない記録を対象とする
+func (s *MedicationService) missedDoseTargets(
+	logs []model.MedicationLog, medications []model.Medication, status *dto.MedicationStatusResponse, at time.Time,
+) []missedDoseTarget {
+	// 薬の指定がない記録（アプリからの服用記録など）は、服用中の薬が1つだけの場合にその薬の記録として扱う
+	defaultMedication := DefaultMedication(medications)
+
+	var targets []missedDoseTarget
+	for _, medication := range medications {
+		// 判定する日より後に登録された薬は対象外
+		if !medication.IsActive || medication.CreatedAt.After(at) {
+			continue
+		}
+
+		medicationID := medication.ID
+		target := missedDoseTarget{
+			medicationID: &medicationID,
+			logs:         logsOfMedication(logs, medication.ID, defaultMedication),
+		}
+		for _, medicationStatus := range status.Medications {
+			if medicationStatus.MedicationID != nil && *medicationStatus.MedicationID == medication.ID {
+				target.isRestPeriod = medicationStatus.IsRestPeriod
+			}
+		}
+		targets = append(targets, target)
+	}
+
+	if len(targets) > 0 {
+		return targets
+	}
+
+	target := missedDoseTarget{isRestPeriod: status.IsRestPeriod}
+	for _, log := range logs {
+		if log.MedicationID == nil {
+			target.logs = append(target.logs, log)
+		}
+	}
+	return []missedDoseTarget{target}
+}
+
+// isMissedDose は指定日が飲み忘れかどうかを判定する
+func (s *MedicationService) isMissedDose(target missedDoseTarget, day time.Time) bool {
+	if target.isRestPeriod {
+		return false
+	}
+
+	lookbackFrom := day.AddDate(0, 0, -missedDoseLookbackDays)
+	hasRecentLog := false
+	for _, log := range target.logs {
+		logDay := startOfDay(log.CreatedAt)
+
+		// 既に服用記録または飲み忘れの記録がある
+		if logDay.Equal(day) {
+			return false
+		}
+
+		if !log.IsMissed && !logDay.Before(lookbackFrom) && logDay.Before(day) {
+			hasRecentLog = true
+		}
+	}
+	return hasRecentLog
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"okusuri-backend/internal/dto"
+	"okusuri-backend/internal/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMedicationService_IsMissedDose(t *testing.T) {
+	service := &MedicationService{}
+	yesterday := time.Date(2025, 6, 9, 0, 0, 0, 0, time.UTC)
+
+	t.Run("前日に記録がなく直近に服用していれば飲み忘れ", func(t *testing.T) {
+		target := missedDoseTarget{logs: dailyLogs(yesterday.AddDate(0, 0, -1).Add(21*time.Hour), 5)}
+		assert.True(t, service.isMissedDose(target, yesterday))
+	})
+
+	t.Run("前日に服用記録があれば飲み忘れではない", func(t *testing.T) {
+		target := missedDoseTarget{logs: dailyLogs(yesterday.Add(21*time.Hour), 5)}
+		assert.False(t, service.isMissedDose(target, yesterday))
+	})
+
+	t.Run("前日に飲み忘れの記録が既にあれば重複しない", func(t *testing.T) {
+		logs := append(
+			[]model.MedicationLog{{CreatedAt: yesterday.Add(24*time.Hour - time.Second), IsMissed: true}},
+			dailyLogs(yesterday.AddDate(0, 0, -1), 5)...,
+		)
+		assert.False(t, service.isMissedDose(missedDoseTarget{logs: logs}, yesterday))
+	})
+
+	t.Run("休薬期間中は飲み忘れではない", func(t *testing.T) {
+		target := missedDoseTarget{logs: dailyLogs(yesterday.AddDate(0, 0, -1), 5), isRestPeriod: true}
+		assert.False(t, service.isMissedDose(target, yesterday))
+	})
+
+	t.Run("しばらく服用記録がないユーザーは対象外", func(t *testing.T) {
+		target := missedDoseTarget{logs: dailyLogs(yesterday.AddDate(0, 0, -10), 5)}
+		assert.False(t, service.isMissedDose(target, yesterday))
+	})
+}
+
+func TestMedicationService_MissedDoseTargets(t *testing.T) {
+	service := &MedicationService{}
+	at := time.Date(2025, 6, 9, 23, 59, 59, 0, time.UTC)
+	pillID := uint(1)
+
+	t.Run("薬が未登録の場合は薬の指定がない記録が対象", func(t *testing.T) {
+		status := &dto.MedicationStatusResponse{IsRestPeriod: true}
+		targets := service.missedDoseTargets(dailyLogs(at, 3), nil, status, at)
+
+		assert.Len(t, targets, 1)
+		assert.Nil(t, targets[0].medicationID)
+		assert.Len(t, targets[0].logs, 3)
+		assert.True(t, targets[0].isRestPeriod)
+	})
+
+	t.Run("服用中の薬ごとに判定する", func(t *testing.T) {
+		medications := []model.Medication{
+			{ID: pillID, IsActive: true, CreatedAt: at.AddDate(0, -1, 0)},
+			{ID: 2, IsActive: false, CreatedAt: at.AddDate(0, -1, 0)},
+			{ID: 3, IsActive: true, CreatedAt: at.Add(time.Hour)},
+		}
+		status := &dto.MedicationStatusResponse{
+			Medications: []dto.MedicationStatusResponse{{MedicationID: &pillID, IsRestPeriod: true}},
+		}
+		logs := []model.MedicationLog{{CreatedAt: at, MedicationID: &pillID}, {CreatedAt: at}}
+		targets := service.missedDoseTargets(logs, medications, status, at)
+
+		assert.Len(t, targets, 1)
+		assert.Equal(t, pillID, *targets[0].medicationID)
+		assert.Len(t, targets[0].logs, 1)
+		assert.True(t, targets[0].isRestPeriod)
+	})
+
+	t.Run("服用中の薬が1つだけの場合は薬の指定がない記録もその薬の記録として扱う", func(t *testing.T) {
+		monday := time.Date(2025, 6, 9, 21, 0, 0, 0, time.UTC)
+		tuesday := time.Date(2025, 6, 10, 0, 0, 0, 0, time.UTC)
+		medications := []model.Medication{{ID: pillID, IsActive: true, CreatedAt: monday.AddDate(0, -1, 0)}}
+		status := &dto.MedicationStatusResponse{
+			Medications: []dto.MedicationStatusResponse{{MedicationID: &pillID}},
+		}
+		// 月曜日は通知のアクション（薬の指定あり）、火曜日はアプリ（薬の指定なし）から記録
+		logs := []model.MedicationLog{
+			{CreatedAt: tuesday.Add(21 * time.Hour)},
+			{CreatedAt: monday, MedicationID: &pillID},
+		}
+		targets := service.missedDoseTargets(logs, medications, status, tuesday.Add(24*time.Hour-time.Second))
+
+		assert.Len(t, targets, 1)
+		assert.Len(t, targets[0].logs, 2)
+		assert.False(t, service.isMissedDose(targets[0], tuesday))
+	})
+}
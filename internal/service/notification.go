@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"okusuri-backend/internal/model"
+	"okusuri-backend/pkg/clock"
 	"os"
 	"sync"
 	"time"
@@ -13,6 +14,11 @@ import (
 
 // NotificationService は通知を送信するサービス
 type NotificationService struct {
+	clock clock.Clock
+
+	// 通知のアクショントークンの署名に用いる鍵（未設定の場合はアクションボタンを表示しない）
+	actionSecret []byte
+
 	// 直近に送信したサブスクリプションとタイムスタンプを保持するマップ
 	recentSends     map[string]time.Time
 	recentSendMutex sync.Mutex
@@ -29,19 +35,35 @@ type PushSubscription struct {
 
 // 通知データの構造体
 type NotificationData struct {
-	Title string            `json:"title"`
-	Body  string            `json:"body"`
-	Data  map[string]string `json:"data,omitempty"`
+	Title   string               `json:"title"`
+	Body    string               `json:"body"`
+	Data    map[string]string    `json:"data,omitempty"`
+	Actions []NotificationAction `json:"actions,omitempty"`
+}
+
+// ReminderSlot は通知の種類と通知時刻（ユーザーのタイムゾーンの日時）
+type ReminderSlot struct {
+	Kind         string
+	Slot         string
+	MedicationID *uint // アクションで服用記録を登録する薬
 }
 
 // 新しいNotificationServiceのインスタンスを作成
-func NewNotificationService() *NotificationService {
+func NewNotificationService(clk clock.Clock) *NotificationService {
 	return &NotificationService{
-		recentSends: make(map[string]time.Time),
+		clock:        clk,
+		actionSecret: []byte(os.Getenv("NOTIFICATION_ACTION_SECRET")),
+		recentSends:  make(map[string]time.Time),
 	}
 }
 
-// 最近送信した通知かどうかをチェック（5分以内に同じサブスクリプションに送信したか）
+// Now は現在時刻を返す
+func (s *NotificationService) Now() time.Time {
+	return s.clock.Now()
+}
+
+// 最近送信した通知かどうかをチェック（5分以内に同じ送信キーで送信したか）
+// 送信キーはサブスクリプションと通知の種類・通知時刻の組み合わせのため、再通知など意図した別の通知は抑止しない
 func (s *NotificationService) isRecentlySent(subKey string) bool {
 	s.recentSendMutex.Lock()
 	defer s.recentSendMutex.Unlock()
@@ -52,7 +74,7 @@ func (s *NotificationService) isRecentlySent(subKey string) bool {
 	}
 
 	// 5分以内の送信なら重複とみなす
-	timeSinceLast := time.Since(lastSent)
+	timeSinceLast := s.clock.Now().Sub(lastSent)
 	fmt.Printf(">> 前回の送信からの経過時間: %v (サブスクリプション: %s...)\n",
 		timeSinceLast.Round(time.Second), subKey[:10])
 	return timeSinceLast < 5*time.Minute
@@ -63,12 +85,13 @@ func (s *NotificationService) markAsSent(subKey string) {
 	s.recentSendMutex.Lock()
 	defer s.recentSendMutex.Unlock()
 
-	s.recentSends[subKey] = time.Now()
+	now := s.clock.Now()
+	s.recentSends[subKey] = now
 	fmt.Printf(">> サブスクリプション %s... を送信済みとしてマークしました\n", subKey[:10])
 
 	// 古い記録をクリーンアップ（1時間以上前のものを削除）
 	for key, lastSent := range s.recentSends {
-		if time.Since(lastSent) > time.Hour {
+		if now.Sub(lastSent) > time.Hour {
 			delete(s.recentSends, key)
 			fmt.Printf(">> 古い送信記録を削除: %s...\n", key[:10])
 		}
@@ -78,6 +101,105 @@ func (s *NotificationService) markAsSent(subKey string) {
 // SendNotificationWithDays は連続服薬日数を含めて通知を送信する
 func (s *NotificationService) SendNotificationWithDays(
 	user model.User, setting model.NotificationSetting, message string, consecutiveDays int,
+) error {
+	notificationData, err := s.medicationNotificationData(user, message, consecutiveDays, nil)
+	if err != nil {
+		return err
+	}
+
+	return s.sendPush(user, setting, "", notificationData)
+}
+
+// SendSlotNotification は通知の種類と通知時刻を指定して服薬の通知を送信する
+// 重複送信は種類と通知時刻ごとに判定するため、同じサブスクリプションへの再通知や別の時刻の通知は抑止されない
+func (s *NotificationService) SendSlotNotification(
+	user model.User, setting model.NotificationSetting, message string, consecutiveDays int, slot ReminderSlot,
+) error {
+	notificationData, err := s.medicationNotificationData(user, message, consecutiveDays, slot.MedicationID)
+	if err != nil {
+		return err
+	}
+	notificationData.Data["kind"] = slot.Kind
+	notificationData.Data["slot"] = slot.Slot
+
+	return s.sendPush(user, setting, slot.Kind+"-"+slot.Slot, notificationData)
+}
+
+// medicationNotificationData は服薬の通知内容を作成する（連続服薬日数を含める）
+// アクショントークンを発行できる場合は、通知から服用記録を登録できるようアクションボタンを含める
+func (s *NotificationService) medicationNotificationData(
+	user model.User, message string, consecutiveDays int, medicationID *uint,
+) (NotificationData, error) {
+	now := s.clock.Now()
+	notificationData := NotificationData{
+		Title: "お薬通知",
+		Body:  message,
+		Data: map[string]string{
+			"messageId":       fmt.Sprintf("medication-%d", now.UnixNano()),
+			"timestamp":       fmt.Sprintf("%d", now.Unix()),
+			"userId":          user.ID,
+			"consecutiveDays": fmt.Sprintf("%d", consecutiveDays),
+		},
+	}
+
+	token, err := s.IssueActionToken(user.ID, medicationID)
+	if err != nil {
+		return notificationData, fmt.Errorf("アクショントークンの発行に失敗: %v", err)
+	}
+	if token != "" {
+		notificationData.Data["actionToken"] = token
+		notificationData.Data["actionUrl"] = NotificationActionPath
+		notificationData.Actions = notificationActions()
+	}
+
+	return notificationData, nil
+}
+
+// SendAppointmentReminder は受診予定のリマインダーを送信する
+func (s *NotificationService) SendAppointmentReminder(
+	user model.User, setting model.NotificationSetting, appointment model.Appointment,
+) error {
+	now := s.clock.Now()
+	notificationData := NotificationData{
+		Title: "受診の予定",
+		Body:  appointmentReminderMessage(appointment, now.In(user.Location())),
+		Data: map[string]string{
+			"messageId":     fmt.Sprintf("appointment-%d-%d", appointment.ID, now.UnixNano()),
+			"timestamp":     fmt.Sprintf("%d", now.Unix()),
+			"userId":        user.ID,
+			"appointmentId": fmt.Sprintf("%d", appointment.ID),
+		},
+	}
+
+	// 服薬の通知と同時刻でも抑止されないよう、受診予定ごとに重複を判定する
+	return s.sendPush(user, setting, fmt.Sprintf("appointment-%d", appointment.ID), notificationData)
+}
+
+// appointmentReminderMessage は受診予定のリマインダーのメッセージを生成する（nowはユーザーのタイムゾーン）
+func appointmentReminderMessage(appointment model.Appointment, now time.Time) string {
+	scheduledAt := appointment.ScheduledAt.In(now.Location())
+
+	var when string
+	switch daysBetween(now, scheduledAt) {
+	case 0:
+		when = "本日" + scheduledAt.Format("15:04")
+	case 1:
+		when = "明日" + scheduledAt.Format("15:04")
+	default:
+		when = scheduledAt.Format("1月2日 15:04")
+	}
+
+	message := fmt.Sprintf("%sに「%s」の受診予定があります。", when, appointment.Clinic)
+	if appointment.Purpose != "" {
+		message += fmt.Sprintf("（%s）", appointment.Purpose)
+	}
+	return message
+}
+
+// sendPush はWeb Push通知を送信する
+// dedupKeyはサブスクリプションごとの重複判定に加えるキー（空の場合はサブスクリプション単位で判定する）
+func (s *NotificationService) sendPush(
+	user model.User, setting model.NotificationSetting, dedupKey string, notificationData NotificationData,
 ) error {
 	// subscriptionが空の場合
 	if setting.Subscription == "" {
@@ -103,6 +225,9 @@ func (s *NotificationService) SendNotificationWithDays(
 
 	// 最近送信済みなら重複送信をスキップ
 	subKey := subscription.Endpoint
+	if dedupKey != "" {
+		subKey = subKey + "#" + dedupKey
+	}
 	if s.isRecentlySent(subKey) {
 		fmt.Printf(">> 通知サービス: サブスクリプション %s は最近送信済みのためスキップします\n",
 			subscriptionPreview)
@@ -118,18 +243,6 @@ func (s *NotificationService) SendNotificationWithDays(
 		return fmt.Errorf("VAPID鍵が設定されていません")
 	}
 
-	// 通知内容の作成（連続服薬日数を含める）
-	notificationData := NotificationData{
-		Title: "お薬通知",
-		Body:  message,
-		Data: map[string]string{
-			"messageId":       fmt.Sprintf("medication-%d", time.Now().UnixNano()),
-			"timestamp":       fmt.Sprintf("%d", time.Now().Unix()),
-			"userId":          user.ID,
-			"consecutiveDays": fmt.Sprintf("%d", consecutiveDays),
-		},
-	}
-
 	// 通知内容をJSONに変換
 	payload, err := json.Marshal(notificationData)
 	if err != nil {
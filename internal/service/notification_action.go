@@ -0,0 +1,114 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 通知から実行できるアクション
+const (
+	NotificationActionTaken         = "taken"          // 服用した
+	NotificationActionTakenBleeding = "taken_bleeding" // 服用した（出血あり）
+	NotificationActionSnooze        = "snooze"         // 15分後に再通知
+)
+
+// actionTokenTTL はアクショントークンの有効期間
+const actionTokenTTL = 6 * time.Hour
+
+// SnoozeDuration はスヌーズしてから再通知するまでの時間
+const SnoozeDuration = 15 * time.Minute
+
+// NotificationActionPath は通知のアクションを受け付けるエンドポイント
+const NotificationActionPath = "/api/notification/action"
+
+// 通知のアクションボタン
+type NotificationAction struct {
+	Action string `json:"action"`
+	Title  string `json:"title"`
+}
+
+// ActionClaims はアクショントークンに含める内容
+type ActionClaims struct {
+	UserID       string `json:"uid"`
+	MedicationID *uint  `json:"mid,omitempty"` // 服用記録を登録する薬（未指定の場合は薬の指定なし）
+	ExpiresAt    int64  `json:"exp"`
+	Nonce        string `json:"nonce"` // トークンを1度だけ使えるようにするための識別子
+}
+
+// notificationActions は通知に表示するアクションボタンを返す
+func notificationActions() []NotificationAction {
+	return []NotificationAction{
+		{Action: NotificationActionTaken, Title: "服用した"},
+		{Action: NotificationActionTakenBleeding, Title: "服用した（出血あり）"},
+		{Action: NotificationActionSnooze, Title: "15分後に再通知"},
+	}
+}
+
+// IssueActionToken はセッションなしで通知のアクションを実行するための署名付きトークンを発行する
+// 署名用の鍵が設定されていない場合は空文字を返す
+func (s *NotificationService) IssueActionToken(userID string, medicationID *uint) (string, error) {
+	if len(s.actionSecret) == 0 {
+		return "", nil
+	}
+
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	claims := ActionClaims{
+		UserID:       userID,
+		MedicationID: medicationID,
+		ExpiresAt:    s.clock.Now().Add(actionTokenTTL).Unix(),
+		Nonce:        hex.EncodeToString(nonce),
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + s.signActionToken(encoded), nil
+}
+
+// VerifyActionToken はアクショントークンの署名と有効期限を検証する
+func (s *NotificationService) VerifyActionToken(token string) (*ActionClaims, error) {
+	if len(s.actionSecret) == 0 {
+		return nil, fmt.Errorf("action token is not configured")
+	}
+
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(signature), []byte(s.signActionToken(encoded))) {
+		return nil, fmt.Errorf("invalid action token signature")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("malformed action token")
+	}
+
+	var claims ActionClaims
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.UserID == "" || claims.Nonce == "" {
+		return nil, fmt.Errorf("malformed action token")
+	}
+
+	if s.clock.Now().Unix() >= claims.ExpiresAt {
+		return nil, fmt.Errorf("action token expired")
+	}
+
+	return &claims, nil
+}
+
+// signActionToken はHMAC-SHA256で署名する
+func (s *NotificationService) signActionToken(encoded string) string {
+	mac := hmac.New(sha256.New, s.actionSecret)
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
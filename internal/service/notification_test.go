@@ -2,8 +2,10 @@ package service
 
 import (
 	"testing"
+	"time"
 
 	"okusuri-backend/internal/model"
+	"okusuri-backend/pkg/clock"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -11,7 +13,7 @@ import (
 func TestNotificationService_New(t *testing.T) {
 	t.Run("NotificationServiceが正常に作成される", func(t *testing.T) {
 		// NotificationServiceの作成をテスト
-		service := NewNotificationService()
+		service := NewNotificationService(clock.New())
 
 		assert.NotNil(t, service)
 		assert.NotNil(t, service.recentSends)
@@ -19,7 +21,7 @@ func TestNotificationService_New(t *testing.T) {
 }
 
 func TestNotificationService_RecentSendCheck(t *testing.T) {
-	service := NewNotificationService()
+	service := NewNotificationService(clock.New())
 
 	t.Run("初回送信は重複ではない", func(t *testing.T) {
 		// 初回送信は重複とみなされない
@@ -35,10 +37,27 @@ func TestNotificationService_RecentSendCheck(t *testing.T) {
 		isRecent := service.isRecentlySent("test-subscription-key")
 		assert.True(t, isRecent)
 	})
+
+	t.Run("通知の種類や時刻が異なる送信キーは重複ではない", func(t *testing.T) {
+		service.markAsSent("test-subscription-key#daily-2025-06-10T21:00")
+		assert.False(t, service.isRecentlySent("test-subscription-key#follow_up-2025-06-10T21:00/1"))
+	})
+
+	t.Run("5分経過後は重複ではない", func(t *testing.T) {
+		clk := clock.NewFixed(time.Date(2025, 6, 10, 21, 0, 0, 0, time.UTC))
+		service := NewNotificationService(clk)
+
+		service.markAsSent("test-subscription-key")
+		clk.Advance(4 * time.Minute)
+		assert.True(t, service.isRecentlySent("test-subscription-key"))
+
+		clk.Advance(time.Minute)
+		assert.False(t, service.isRecentlySent("test-subscription-key"))
+	})
 }
 
 func TestNotificationService_SendNotification(t *testing.T) {
-	service := NewNotificationService()
+	service := NewNotificationService(clock.New())
 
 	t.Run("空のサブスクリプションでエラー", func(t *testing.T) {
 		user := model.User{ID: "test-user"}
@@ -56,7 +75,7 @@ func TestNotificationService_SendNotification(t *testing.T) {
 }
 
 func TestNotificationService_SendNotificationWithDays(t *testing.T) {
-	service := NewNotificationService()
+	service := NewNotificationService(clock.New())
 
 	t.Run("空のサブスクリプションでエラー", func(t *testing.T) {
 		user := model.User{ID: "test-user"}
@@ -72,3 +91,109 @@ func TestNotificationService_SendNotificationWithDays(t *testing.T) {
 		assert.Contains(t, err.Error(), "サブスクリプションが見つかりません")
 	})
 }
+
+func TestAppointmentReminderMessage(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	assert.NoError(t, err)
+	now := time.Date(2025, 6, 10, 9, 0, 0, 0, tokyo)
+
+	t.Run("翌日の予定は明日として通知する", func(t *testing.T) {
+		appointment := model.Appointment{
+			ScheduledAt: time.Date(2025, 6, 11, 1, 30, 0, 0, time.UTC),
+			Clinic:      "さくらクリニック",
+			Purpose:     "血液検査",
+		}
+
+		message := appointmentReminderMessage(appointment, now)
+		assert.Equal(t, "明日10:30に「さくらクリニック」の受診予定があります。（血液検査）", message)
+	})
+
+	t.Run("2日以上先の予定は日付で通知する", func(t *testing.T) {
+		appointment := model.Appointment{
+			ScheduledAt: time.Date(2025, 6, 13, 14, 0, 0, 0, tokyo),
+			Clinic:      "さくらクリニック",
+		}
+
+		message := appointmentReminderMessage(appointment, now)
+		assert.Equal(t, "6月13日 14:00に「さくらクリニック」の受診予定があります。", message)
+	})
+}
+
+func TestNotificationService_SendAppointmentReminder(t *testing.T) {
+	service := NewNotificationService(clock.New())
+
+	t.Run("空のサブスクリプションでエラー", func(t *testing.T) {
+		user := model.User{ID: "test-user"}
+		setting := model.NotificationSetting{UserID: "test-user", Platform: "web", IsEnabled: true}
+
+		err := service.SendAppointmentReminder(user, setting, model.Appointment{ID: 1, Clinic: "さくらクリニック"})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "サブスクリプションが見つかりません")
+	})
+}
+
+func TestNotificationService_ActionToken(t *testing.T) {
+	clk := clock.NewFixed(time.Date(2025, 6, 10, 21, 0, 0, 0, time.UTC))
+	service := NewNotificationService(clk)
+	service.actionSecret = []byte("test-secret")
+	medicationID := uint(3)
+
+	t.Run("発行したトークンを検証できる", func(t *testing.T) {
+		token, err := service.IssueActionToken("test-user", &medicationID)
+		assert.NoError(t, err)
+
+		claims, err := service.VerifyActionToken(token)
+		assert.NoError(t, err)
+		assert.Equal(t, "test-user", claims.UserID)
+		assert.Equal(t, medicationID, *claims.MedicationID)
+		assert.NotEmpty(t, claims.Nonce)
+	})
+
+	t.Run("改ざんされたトークンはエラー", func(t *testing.T) {
+		token, err := service.IssueActionToken("test-user", nil)
+		assert.NoError(t, err)
+
+		other := NewNotificationService(clk)
+		other.actionSecret = []byte("other-secret")
+		_, err = other.VerifyActionToken(token)
+		assert.Error(t, err)
+
+		_, err = service.VerifyActionToken("x" + token)
+		assert.Error(t, err)
+	})
+
+	t.Run("有効期限を過ぎたトークンはエラー", func(t *testing.T) {
+		clk := clock.NewFixed(time.Date(2025, 6, 10, 21, 0, 0, 0, time.UTC))
+		service := NewNotificationService(clk)
+		service.actionSecret = []byte("test-secret")
+
+		token, err := service.IssueActionToken("test-user", nil)
+		assert.NoError(t, err)
+
+		clk.Advance(actionTokenTTL)
+		_, err = service.VerifyActionToken(token)
+		assert.Error(t, err)
+	})
+
+	t.Run("鍵が設定されていない場合はアクションボタンを含めない", func(t *testing.T) {
+		service := NewNotificationService(clk)
+		service.actionSecret = nil
+
+		data, err := service.medicationNotificationData(model.User{ID: "test-user"}, "テストメッセージ", 0, nil)
+		assert.NoError(t, err)
+		assert.Empty(t, data.Actions)
+		assert.NotContains(t, data.Data, "actionToken")
+	})
+
+	t.Run("鍵が設定されている場合はアクションボタンとトークンを含める", func(t *testing.T) {
+		data, err := service.medicationNotificationData(model.User{ID: "test-user"}, "テストメッセージ", 0, nil)
+		assert.NoError(t, err)
+		assert.Len(t, data.Actions, 3)
+		assert.Equal(t, NotificationActionTaken, data.Actions[0].Action)
+		assert.Equal(t, NotificationActionPath, data.Data["actionUrl"])
+
+		claims, err := service.VerifyActionToken(data.Data["actionToken"])
+		assert.NoError(t, err)
+		assert.Equal(t, "test-user", claims.UserID)
+	})
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"math"
+	"okusuri-backend/internal/dto"
+	"okusuri-backend/internal/model"
+	"time"
+)
+
+const (
+	// predictionMinCycles は予測に必要な過去の周期の数
+	predictionMinCycles = 2
+	// predictionMaxCycles は予測に用いる直近の周期の数
+	predictionMaxCycles = 6
+)
+
+// predictNextRest は過去の出血による休薬までの服用日数から、次の休薬開始日を予測する
+//
+// 予測値は服用開始から休薬開始までの日数の平均、予測範囲は標準偏差（最低1日）の幅とする。
+// 服用期間中でない場合や、過去の周期が足りない場合はnilを返す。
+func (s *MedicationService) predictNextRest(
+	logs []model.MedicationLog, regimen model.Regimen, timeline flexTimeline, now time.Time,
+) *dto.RestPrediction {
+	if timeline.activeStart.IsZero() {
+		return nil
+	}
+
+	samples := bleedingCycleLengths(buildCyclePhases(s.groupLogsByDay(logs), timeline.restPeriods))
+	if len(samples) < predictionMinCycles {
+		return nil
+	}
+	if len(samples) > predictionMaxCycles {
+		samples = samples[len(samples)-predictionMaxCycles:]
+	}
+
+	mean, stddev := meanAndStddev(samples)
+	margin := math.Max(math.Ceil(stddev), 1)
+
+	today := startOfDay(now)
+	predicted := timeline.activeStart.AddDate(0, 0, int(math.Round(mean)))
+	earliest := timeline.activeStart.AddDate(0, 0, int(math.Round(mean-margin)))
+	latest := timeline.activeStart.AddDate(0, 0, int(math.Round(mean+margin)))
+
+	// 最低連続服用日数に達するまでは出血による休薬は始まらない
+	if regimen.MinActiveDays > 0 {
+		minStart := timeline.activeStart.AddDate(0, 0, regimen.MinActiveDays-1)
+		earliest = latestOf(earliest, minStart)
+		predicted = latestOf(predicted, minStart)
+	}
+
+	// 予測日を過ぎている場合は今日以降に繰り下げる
+	earliest = latestOf(earliest, today)
+	predicted = latestOf(predicted, today)
+	latest = latestOf(latest, predicted)
+
+	// 最大連続服用日数に達すると強制休薬となるため、それより後にはならない
+	if forced := s.forcedRestStart(timeline.activeStart, regimen); !forced.IsZero() {
+		latest = earliestOf(latest, forced)
+		predicted = earliestOf(predicted, latest)
+		earliest = earliestOf(earliest, predicted)
+	}
+
+	return &dto.RestPrediction{
+		PredictedStartAt: predicted,
+		EarliestStartAt:  earliest,
+		LatestStartAt:    latest,
+		SampleCycles:     len(samples),
+		Confidence:       predictionConfidence(len(samples), stddev),
+	}
+}
+
+// bleedingCycleLengths は出血による休薬ごとに、直前の服用期間の開始日から休薬開始日までの日数を古い順に返す
+func bleedingCycleLengths(phases []cyclePhase) []float64 {
+	var lengths []float64
+	for i := 1; i < len(phases); i++ {
+		rest, active := phases[i], phases[i-1]
+		if rest.phase != dto.PhaseRest || rest.forced || active.phase != dto.PhaseActive {
+			continue
+		}
+		// 服用期間から途切れずに休薬に入った周期のみを対象とする
+		if daysBetween(active.end, rest.start) != 1 {
+			continue
+		}
+		lengths = append(lengths, float64(daysBetween(active.start, rest.start)))
+	}
+	return lengths
+}
+
+// meanAndStddev は平均と標準偏差を返す
+func meanAndStddev(values []float64) (float64, float64) {
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+
+	variance := 0.0
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	variance /= float64(len(values))
+
+	return mean, math.Sqrt(variance)
+}
+
+// predictionConfidence は周期の数とばらつきから予測の信頼度を判定する
+func predictionConfidence(samples int, stddev float64) string {
+	switch {
+	case samples >= 3 && stddev <= 3:
+		return dto.PredictionConfidenceHigh
+	case stddev <= 7:
+		return dto.PredictionConfidenceMedium
+	default:
+		return dto.PredictionConfidenceLow
+	}
+}
+
+// latestOf は2つの日時のうち遅い方を返す
+func latestOf(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+// earliestOf は2つの日時のうち早い方を返す
+func earliestOf(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"okusuri-backend/internal/dto"
+	"okusuri-backend/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// cycleLogs は指定した服用日数ごとに3日間の出血で休薬する周期を繰り返し、最後に現在の服用期間を続けたログを作成する（新しい順）
+func cycleLogs(start time.Time, activeDays []int, currentDays int) ([]model.MedicationLog, time.Time) {
+	var logs []model.MedicationLog
+	day := start
+	for _, length := range activeDays {
+		for i := 0; i < length+3; i++ {
+			logs = append([]model.MedicationLog{{CreatedAt: day.Add(8 * time.Hour), HasBleeding: i >= length}}, logs...)
+			day = day.AddDate(0, 0, 1)
+		}
+		// 休薬期間の残り（出血初日から4日後まで）は記録しない
+		day = day.AddDate(0, 0, 2)
+	}
+
+	currentStart := day
+	for i := 0; i < currentDays; i++ {
+		logs = append([]model.MedicationLog{{CreatedAt: day.Add(8 * time.Hour)}}, logs...)
+		day = day.AddDate(0, 0, 1)
+	}
+	return logs, currentStart
+}
+
+func TestMedicationService_PredictNextRest(t *testing.T) {
+	service := &MedicationService{}
+	regimen := model.NewDefaultRegimen("test-user")
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("過去の周期の平均から次の休薬開始日が予測される", func(t *testing.T) {
+		logs, currentStart := cycleLogs(start, []int{20, 22, 21}, 10)
+		now := currentStart.AddDate(0, 0, 9).Add(12 * time.Hour)
+
+		status := service.strategyFor(regimen).CalculateStatus(logs, regimen, now)
+
+		require.NotNil(t, status.NextRestPrediction)
+		prediction := status.NextRestPrediction
+		assert.Equal(t, currentStart.AddDate(0, 0, 21), prediction.PredictedStartAt)
+		assert.Equal(t, currentStart.AddDate(0, 0, 20), prediction.EarliestStartAt)
+		assert.Equal(t, currentStart.AddDate(0, 0, 22), prediction.LatestStartAt)
+		assert.Equal(t, 3, prediction.SampleCycles)
+		assert.Equal(t, dto.PredictionConfidenceHigh, prediction.Confidence)
+	})
+
+	t.Run("過去の周期が足りない場合は予測しない", func(t *testing.T) {
+		logs, currentStart := cycleLogs(start, []int{20}, 10)
+		now := currentStart.AddDate(0, 0, 9).Add(12 * time.Hour)
+
+		status := service.strategyFor(regimen).CalculateStatus(logs, regimen, now)
+		assert.Nil(t, status.NextRestPrediction)
+	})
+
+	t.Run("予測日を過ぎている場合は今日以降に繰り下げ、強制休薬日を超えない", func(t *testing.T) {
+		regimen := model.NewDefaultRegimen("test-user")
+		regimen.MaxContinuousDays = 30
+		logs, currentStart := cycleLogs(start, []int{20, 22, 21}, 25)
+		now := currentStart.AddDate(0, 0, 24).Add(12 * time.Hour)
+		today := currentStart.AddDate(0, 0, 24)
+
+		status := service.strategyFor(regimen).CalculateStatus(logs, regimen, now)
+
+		require.NotNil(t, status.NextRestPrediction)
+		prediction := status.NextRestPrediction
+		assert.Equal(t, today, prediction.PredictedStartAt)
+		assert.Equal(t, today, prediction.EarliestStartAt)
+		assert.Equal(t, today, prediction.LatestStartAt)
+	})
+}
@@ -0,0 +1,235 @@
+package service
+
+import (
+	"okusuri-backend/internal/dto"
+	"okusuri-backend/internal/model"
+	"time"
+)
+
+// RegimenStrategy はレジメンごとの服薬ステータス計算ロジック
+type RegimenStrategy interface {
+	// CalculateStatus は新しい順にソートされたログから服薬ステータスを計算する
+	CalculateStatus(logs []model.MedicationLog, regimen model.Regimen, now time.Time) *dto.MedicationStatusResponse
+
+	// RestPeriods はuntilまでに始まる休薬期間を古い順に返す（nowは履歴を判定する基準日時）
+	RestPeriods(logs []model.MedicationLog, regimen model.Regimen, until, now time.Time) []restPeriod
+}
+
+// strategyFor はレジメンの種類に応じた計算ロジックを返す
+func (s *MedicationService) strategyFor(regimen model.Regimen) RegimenStrategy {
+	switch regimen.Type {
+	case model.RegimenTypeCyclic:
+		if regimen.PackActiveDays > 0 && regimen.PackBreakDays > 0 {
+			return &cyclicStrategy{svc: s}
+		}
+	case model.RegimenTypeContinuous:
+		return &continuousStrategy{svc: s}
+	}
+	return &flexibleStrategy{svc: s}
+}
+
+// flexibleStrategy は連続出血をきっかけに休薬するフレキシブル延長投与
+type flexibleStrategy struct {
+	svc *MedicationService
+}
+
+func (st *flexibleStrategy) CalculateStatus(
+	logs []model.MedicationLog, regimen model.Regimen, now time.Time,
+) *dto.MedicationStatusResponse {
+	response := newStatusResponse(regimen)
+
+	// ログが存在しない場合は初期値を返す
+	if len(logs) == 0 {
+		return response
+	}
+
+	// 休薬期間の判定と連続出血日数の計算
+	isInRestPeriod, restDaysLeft, consecutiveBleedingDays := st.svc.calculateRestPeriodStatus(logs, regimen, now)
+	response.IsRestPeriod = isInRestPeriod
+	response.RestDaysLeft = restDaysLeft
+	response.ConsecutiveBleedingDays = consecutiveBleedingDays
+
+	if isInRestPeriod {
+		// 休薬期間中は休薬何日目かと再開日を返す
+		response.Phase = dto.PhaseRest
+		if rest := st.svc.buildFlexTimeline(logs, regimen, now).restPeriodAt(now); rest != nil {
+			response.DayInPack = daysBetween(rest.Start, now) + 1
+		}
+		resumeAt := startOfDay(now).AddDate(0, 0, restDaysLeft)
+		response.NextPhaseChangeAt = &resumeAt
+		return response
+	}
+
+	// 休薬期間中でなければ、現在の連続服用日数を計算
+	response.CurrentStreak = st.svc.calculateCurrentStreak(logs, regimen, now)
+	response.DayInPack = response.CurrentStreak
+
+	// 最低・最大連続服用日数に基づく休薬の可否と強制休薬日
+	timeline := st.svc.buildFlexTimeline(logs, regimen, now)
+	activeStart := timeline.activeStart
+	response.RestAllowed = activeStart.IsZero() ||
+		daysBetween(activeStart, now)+1 >= regimen.MinActiveDays
+	if forced := st.svc.forcedRestStart(activeStart, regimen); !forced.IsZero() {
+		// 最大連続服用日数に達する場合は強制休薬日が次のフェーズ切り替え日となる
+		response.ForcedRestDueAt = &forced
+		response.NextPhaseChangeAt = &forced
+	}
+
+	// 過去の周期から次の出血による休薬を予測
+	response.NextRestPrediction = st.svc.predictNextRest(logs, regimen, timeline, now)
+	return response
+}
+
+func (st *flexibleStrategy) RestPeriods(
+	logs []model.MedicationLog, regimen model.Regimen, until, now time.Time,
+) []restPeriod {
+	var periods []restPeriod
+	for _, rest := range st.svc.buildFlexTimeline(logs, regimen, now).restPeriods {
+		if rest.Start.After(until) {
+			break
+		}
+		periods = append(periods, rest)
+	}
+	return periods
+}
+
+// cyclicStrategy は21/7や24/4などシート単位で実薬と休薬を繰り返す周期投与
+type cyclicStrategy struct {
+	svc *MedicationService
+}
+
+// packStart は最初のシートの開始日を返す（判定できない場合はゼロ値）
+func (st *cyclicStrategy) packStart(logs []model.MedicationLog, regimen model.Regimen, loc *time.Location) time.Time {
+	// シート開始日が未設定の場合は最初のログの日付を起点とする
+	switch {
+	case regimen.PackStartDate != nil:
+		// シート開始日は暦日として扱い、ユーザーのタイムゾーンの0時に合わせる
+		return regimen.PackStartDate.In(loc)
+	case len(logs) > 0:
+		return startOfDay(logs[len(logs)-1].CreatedAt)
+	}
+	return time.Time{}
+}
+
+func (st *cyclicStrategy) CalculateStatus(
+	logs []model.MedicationLog, regimen model.Regimen, now time.Time,
+) *dto.MedicationStatusResponse {
+	response := newStatusResponse(regimen)
+
+	packStart := st.packStart(logs, regimen, now.Location())
+	if packStart.IsZero() {
+		return response
+	}
+
+	today := startOfDay(now)
+	elapsed := daysBetween(packStart, today)
+	if elapsed < 0 {
+		// シート開始前は服用期間の0日目として扱う
+		nextChange := packStart
+		response.NextPhaseChangeAt = &nextChange
+		return response
+	}
+
+	cycleDays := regimen.PackActiveDays + regimen.PackBreakDays
+	dayInPack := elapsed%cycleDays + 1
+	currentPackStart := today.AddDate(0, 0, -(dayInPack - 1))
+	response.DayInPack = dayInPack
+	_, _, response.ConsecutiveBleedingDays = st.svc.calculateRestPeriodStatus(logs, regimen, now)
+
+	if dayInPack > regimen.PackActiveDays {
+		// 休薬・偽薬期間
+		response.Phase = dto.PhaseRest
+		response.IsRestPeriod = true
+		response.RestDaysLeft = cycleDays - dayInPack + 1
+		nextChange := currentPackStart.AddDate(0, 0, cycleDays)
+		response.NextPhaseChangeAt = &nextChange
+		return response
+	}
+
+	// 実薬期間は今回のシート開始日以降の連続服用日数を数える
+	uniqueDates := st.svc.extractUniqueDates(logs)
+	response.CurrentStreak = st.svc.countConsecutiveDays(uniqueDates, currentPackStart, now)
+	nextChange := currentPackStart.AddDate(0, 0, regimen.PackActiveDays)
+	response.NextPhaseChangeAt = &nextChange
+	return response
+}
+
+func (st *cyclicStrategy) RestPeriods(
+	logs []model.MedicationLog, regimen model.Regimen, until, now time.Time,
+) []restPeriod {
+	packStart := st.packStart(logs, regimen, now.Location())
+	if packStart.IsZero() {
+		return nil
+	}
+
+	// シートごとに実薬期間の翌日から休薬・偽薬期間となる
+	cycleDays := regimen.PackActiveDays + regimen.PackBreakDays
+	var periods []restPeriod
+	for cycleStart := packStart; ; cycleStart = cycleStart.AddDate(0, 0, cycleDays) {
+		restStart := cycleStart.AddDate(0, 0, regimen.PackActiveDays)
+		if restStart.After(until) {
+			break
+		}
+		periods = append(periods, restPeriod{
+			Start: restStart,
+			End:   cycleStart.AddDate(0, 0, cycleDays-1),
+		})
+	}
+	return periods
+}
+
+// continuousStrategy は休薬期間を設けない連続投与
+type continuousStrategy struct {
+	svc *MedicationService
+}
+
+func (st *continuousStrategy) CalculateStatus(
+	logs []model.MedicationLog, regimen model.Regimen, now time.Time,
+) *dto.MedicationStatusResponse {
+	response := newStatusResponse(regimen)
+	response.RestAllowed = false
+
+	// ログが存在しない場合は初期値を返す
+	if len(logs) == 0 {
+		return response
+	}
+
+	_, _, response.ConsecutiveBleedingDays = st.svc.calculateRestPeriodStatus(logs, regimen, now)
+	uniqueDates := st.svc.extractUniqueDates(logs)
+	response.CurrentStreak = st.svc.countConsecutiveDays(uniqueDates, time.Time{}, now)
+	response.DayInPack = response.CurrentStreak
+	return response
+}
+
+func (st *continuousStrategy) RestPeriods(
+	logs []model.MedicationLog, regimen model.Regimen, until, now time.Time,
+) []restPeriod {
+	return nil
+}
+
+// newStatusResponse はデフォルトのレスポンスを作成する
+func newStatusResponse(regimen model.Regimen) *dto.MedicationStatusResponse {
+	return &dto.MedicationStatusResponse{
+		CurrentStreak:           0,
+		IsRestPeriod:            false,
+		RestDaysLeft:            0,
+		ConsecutiveBleedingDays: 0,
+		RegimenType:             regimen.Type,
+		Phase:                   dto.PhaseActive,
+		RestAllowed:             true,
+	}
+}
+
+// startOfDay は日付の0時0分を返す
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// daysBetween は2つの日付の日数差を返す（夏時間の切り替えを考慮して丸める）
+func daysBetween(from, to time.Time) int {
+	hours := startOfDay(to).Sub(startOfDay(from)).Hours()
+	if hours < 0 {
+		return -int(-hours/24 + 0.5)
+	}
+	return int(hours/24 + 0.5)
+}
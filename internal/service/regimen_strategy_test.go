@@ -0,0 +1,101 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"okusuri-backend/internal/dto"
+	"okusuri-backend/internal/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// dailyLogs は指定日から過去に遡って毎日の服用ログを作成する（新しい順）
+func dailyLogs(latest time.Time, days int) []model.MedicationLog {
+	var logs []model.MedicationLog
+	for i := 0; i < days; i++ {
+		logs = append(logs, model.MedicationLog{CreatedAt: latest.AddDate(0, 0, -i)})
+	}
+	return logs
+}
+
+func TestMedicationService_StrategyFor(t *testing.T) {
+	service := &MedicationService{}
+
+	t.Run("種類ごとに計算ロジックが選択される", func(t *testing.T) {
+		regimen := model.NewDefaultRegimen("test-user")
+		assert.IsType(t, &flexibleStrategy{}, service.strategyFor(regimen))
+
+		regimen.Type = model.RegimenTypeContinuous
+		assert.IsType(t, &continuousStrategy{}, service.strategyFor(regimen))
+
+		regimen.Type = model.RegimenTypeCyclic
+		regimen.PackActiveDays = 21
+		regimen.PackBreakDays = 7
+		assert.IsType(t, &cyclicStrategy{}, service.strategyFor(regimen))
+	})
+
+	t.Run("シート日数が未設定の周期投与はフレキシブルとして扱う", func(t *testing.T) {
+		regimen := model.NewDefaultRegimen("test-user")
+		regimen.Type = model.RegimenTypeCyclic
+		assert.IsType(t, &flexibleStrategy{}, service.strategyFor(regimen))
+	})
+}
+
+func TestCyclicStrategy_CalculateStatus(t *testing.T) {
+	service := &MedicationService{}
+	packStart := model.NewDate(2025, 6, 1)
+	regimen := model.NewDefaultRegimen("test-user")
+	regimen.Type = model.RegimenTypeCyclic
+	regimen.PackActiveDays = 21
+	regimen.PackBreakDays = 7
+	regimen.PackStartDate = &packStart
+	strategy := service.strategyFor(regimen)
+
+	t.Run("実薬期間中はシート内の日数と連続服用日数を返す", func(t *testing.T) {
+		now := time.Date(2025, 6, 10, 21, 0, 0, 0, time.UTC)
+		status := strategy.CalculateStatus(dailyLogs(now, 10), regimen, now)
+
+		assert.Equal(t, dto.PhaseActive, status.Phase)
+		assert.Equal(t, 10, status.DayInPack)
+		assert.Equal(t, 10, status.CurrentStreak)
+		assert.False(t, status.IsRestPeriod)
+		assert.Equal(t, time.Date(2025, 6, 22, 0, 0, 0, 0, time.UTC), *status.NextPhaseChangeAt)
+	})
+
+	t.Run("休薬期間中は残り日数を返す", func(t *testing.T) {
+		now := time.Date(2025, 6, 24, 9, 0, 0, 0, time.UTC)
+		status := strategy.CalculateStatus(nil, regimen, now)
+
+		assert.Equal(t, dto.PhaseRest, status.Phase)
+		assert.Equal(t, 24, status.DayInPack)
+		assert.True(t, status.IsRestPeriod)
+		assert.Equal(t, 5, status.RestDaysLeft)
+		assert.Equal(t, time.Date(2025, 6, 29, 0, 0, 0, 0, time.UTC), *status.NextPhaseChangeAt)
+	})
+
+	t.Run("次のシートに入ると1日目に戻る", func(t *testing.T) {
+		now := time.Date(2025, 6, 29, 9, 0, 0, 0, time.UTC)
+		status := strategy.CalculateStatus(nil, regimen, now)
+
+		assert.Equal(t, dto.PhaseActive, status.Phase)
+		assert.Equal(t, 1, status.DayInPack)
+	})
+}
+
+func TestContinuousStrategy_CalculateStatus(t *testing.T) {
+	service := &MedicationService{}
+	regimen := model.NewDefaultRegimen("test-user")
+	regimen.Type = model.RegimenTypeContinuous
+
+	t.Run("出血が続いても休薬期間にならない", func(t *testing.T) {
+		now := time.Date(2025, 6, 10, 9, 0, 0, 0, time.UTC)
+		status := service.strategyFor(regimen).CalculateStatus(bleedingLogs(now, 5), regimen, now)
+
+		assert.False(t, status.IsRestPeriod)
+		assert.Equal(t, dto.PhaseActive, status.Phase)
+		assert.Equal(t, 5, status.CurrentStreak)
+		assert.Equal(t, 5, status.ConsecutiveBleedingDays)
+		assert.Nil(t, status.NextPhaseChangeAt)
+	})
+}
@@ -0,0 +1,605 @@
+package service
+
+import (
+	"fmt"
+	"okusuri-backend/internal/dto"
+	"okusuri-backend/internal/model"
+	"okusuri-backend/internal/repository"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 最大連続服用日数の何日前から休薬を予告するか
+const forcedRestWarningDays = 7
+
+// 処方箋の有効期限の何日前から通知するか
+const prescriptionExpiryWarningDays = 14
+
+// reminderCatchUpMinutes は通知時刻を過ぎてから何分以内であれば通知を送るか
+// サーバーの再起動などでスケジューラーが止まっていた場合でも通知を取りこぼさないようにする
+const reminderCatchUpMinutes = 10
+
+// dispatchRetention は通知の送信記録を保持する期間
+const dispatchRetention = 7 * 24 * time.Hour
+
+// 通知の送信記録の種類
+const (
+	reminderKindDaily    = "daily"     // 通知時刻の服薬リマインダー
+	reminderKindFollowUp = "follow_up" // 服用記録がない場合の再通知
+	reminderKindSnooze   = "snooze"    // スヌーズによる再通知
+	reminderKindAction   = "action"    // 通知のアクションの実行（トークンごとに1度だけ受け付ける）
+)
+
+// followUpMessage は再通知のメッセージ
+const followUpMessage = "まだ今日の服用記録がありません。服用したら記録してください。"
+
+// snoozeMessage はスヌーズによる再通知のメッセージ
+const snoozeMessage = "お薬の時間です。服用したら記録してください。"
+
+// reminderSlotLayout は送信記録に用いる通知時刻の書式（ユーザーのタイムゾーンの日時）
+const reminderSlotLayout = "2006-01-02T15:04"
+
+// ReminderService はユーザーの服薬状況に応じた通知メッセージを作成し、通知時刻に送信するサービス
+type ReminderService struct {
+	userRepo         *repository.UserRepository
+	notificationRepo *repository.NotificationRepository
+	medicationRepo   *repository.MedicationRepository
+	prescriptionRepo *repository.PrescriptionRepository
+	medicationSvc    *MedicationService
+	notificationSvc  *NotificationService
+}
+
+// NewReminderService は新しいReminderServiceのインスタンスを作成
+func NewReminderService(
+	userRepo *repository.UserRepository,
+	notificationRepo *repository.NotificationRepository,
+	medicationRepo *repository.MedicationRepository,
+	prescriptionRepo *repository.PrescriptionRepository,
+	medicationSvc *MedicationService,
+	notificationSvc *NotificationService,
+) *ReminderService {
+	return &ReminderService{
+		userRepo:         userRepo,
+		notificationRepo: notificationRepo,
+		medicationRepo:   medicationRepo,
+		prescriptionRepo: prescriptionRepo,
+		medicationSvc:    medicationSvc,
+		notificationSvc:  notificationSvc,
+	}
+}
+
+// composeMessage は通知メッセージと連続服薬日数を返す
+func (s *ReminderService) composeMessage(user model.User) (string, int) {
+	message := "お薬の時間です。忘れずに服用してください。"
+	medicationStatus, statusErr := s.medicationSvc.GetMedicationStatus(user.ID)
+	if statusErr == nil {
+		regimen, regimenErr := s.medicationSvc.GetRegimen(user.ID)
+		if regimenErr != nil {
+			regimen = model.NewDefaultRegimen(user.ID)
+		}
+		message = generateStatusBasedMessage(medicationStatus, regimen, s.notificationSvc.Now())
+	}
+
+	// 在庫が少なくなっている薬がある場合は補充を促す
+	medications, medicationErr := s.medicationRepo.GetMedicationsByUserID(user.ID)
+	if medicationErr == nil {
+		if reminder := generateRefillReminder(medications); reminder != "" {
+			message = message + "\n" + reminder
+		}
+	}
+
+	// 処方箋の有効期限やリフィル回数が残り少ない場合は受診を促す
+	prescriptions, prescriptionErr := s.prescriptionRepo.GetByUserID(user.ID)
+	if prescriptionErr == nil {
+		now := s.notificationSvc.Now().In(user.Location())
+		if alert := generatePrescriptionAlert(prescriptions, medications, now); alert != "" {
+			message = message + "\n" + alert
+		}
+	}
+
+	consecutiveDays := 0
+	if statusErr == nil {
+		consecutiveDays = medicationStatus.CurrentStreak
+	}
+	return message, consecutiveDays
+}
+
+// DispatchDueReminders はユーザーのタイムゾーンで通知時刻を迎えたユーザーに通知を送信し、送信した件数を返す
+// 送信前に送信記録を登録するため、複数のサーバーで同時に実行しても同じ通知は1度だけ送られる
+func (s *ReminderService) DispatchDueReminders() (int, error) {
+	now := s.notificationSvc.Now()
+
+	users, err := s.userRepo.GetAllUsers()
+	if err != nil {
+		return 0, err
+	}
+	settings, err := s.notificationRepo.GetAllSettings()
+	if err != nil {
+		return 0, err
+	}
+	settingsMap := LatestSettingsByUser(settings)
+
+	sent := 0
+	for _, user := range users {
+		setting, ok := settingsMap[user.ID]
+		if !ok || !setting.IsEnabled || setting.Subscription == "" {
+			continue
+		}
+
+		medications, err := s.medicationRepo.GetMedicationsByUserID(user.ID)
+		if err != nil {
+			fmt.Printf("通知スケジューラー: ユーザーID: %s の薬の取得に失敗: %v\n", user.ID, err)
+			continue
+		}
+
+		// 通知時刻が設定されていない場合は服用予定時刻に通知する
+		times := []string(setting.ReminderTimes)
+		if len(times) == 0 {
+			times = reminderTimes(medications)
+		}
+
+		local := now.In(setting.Location(user.Location()))
+		if slot, due := dueReminderSlot(times, setting, local); due {
+			medicationID := actionMedicationID(medications, slot[len(slot)-len("15:04"):])
+			if s.dispatch(user, setting, ReminderSlot{Kind: reminderKindDaily, Slot: slot, MedicationID: medicationID}, now) {
+				sent++
+			}
+		}
+
+		// 通知後に服用記録がない場合は再通知する
+		if slotAt, attempt, due := dueFollowUp(times, setting, local); due && s.needsFollowUp(user, slotAt) {
+			slot := ReminderSlot{
+				Kind:         reminderKindFollowUp,
+				Slot:         fmt.Sprintf("%s/%d", slotAt.Format(reminderSlotLayout), attempt),
+				MedicationID: actionMedicationID(medications, slotAt.Format("15:04")),
+			}
+			if s.dispatch(user, setting, slot, now) {
+				sent++
+			}
+		}
+	}
+
+	return sent, nil
+}
+
+// dispatch は送信記録を登録し、他のサーバーが送信済みでなければ通知を送信する（送信した場合はtrue）
+func (s *ReminderService) dispatch(user model.User, setting model.NotificationSetting, slot ReminderSlot, now time.Time) bool {
+	claimed, err := s.notificationRepo.ClaimDispatch(user.ID, slot.Kind, slot.Slot, now)
+	if err != nil {
+		fmt.Printf("通知スケジューラー: ユーザーID: %s の送信記録に失敗: %v\n", user.ID, err)
+		return false
+	}
+	if !claimed {
+		return false
+	}
+
+	var message string
+	consecutiveDays := 0
+	if slot.Kind == reminderKindFollowUp {
+		message = followUpMessage
+	} else {
+		message, consecutiveDays = s.composeMessage(user)
+	}
+
+	if err := s.notificationSvc.SendSlotNotification(user, setting, message, consecutiveDays, slot); err != nil {
+		fmt.Printf("通知スケジューラー: ユーザーID: %s への通知送信失敗: %v\n", user.ID, err)
+		return false
+	}
+	return true
+}
+
+// needsFollowUp は通知時刻の通知を送信済みで、その日の服用記録がなく、休薬期間中でない場合にtrueを返す
+// 服用記録を登録した時点で以降の再通知は送られなくなる
+func (s *ReminderService) needsFollowUp(user model.User, slotAt time.Time) bool {
+	sent, err := s.notificationRepo.HasDispatch(user.ID, reminderKindDaily, slotAt.Format(reminderSlotLayout))
+	if err != nil || !sent {
+		return false
+	}
+
+	// 服用記録の日付の境界は、他の服薬状況の判定と同じくユーザーのタイムゾーンで判定する
+	logged, err := s.medicationRepo.HasLogSince(user.ID, startOfDay(slotAt.In(user.Location())))
+	if err != nil || logged {
+		return false
+	}
+
+	status, err := s.medicationSvc.GetMedicationStatus(user.ID)
+	if err != nil || status.IsRestPeriod {
+		return false
+	}
+	return true
+}
+
+// PurgeDispatches は保持期間を過ぎた通知の送信記録を削除する
+func (s *ReminderService) PurgeDispatches() error {
+	return s.notificationRepo.DeleteDispatchesBefore(s.notificationSvc.Now().Add(-dispatchRetention))
+}
+
+// DispatchSnoozedReminders はスヌーズした日時を迎えた通知を送信し、送信した件数を返す
+// 送信前に送信済みとして記録するため、複数のサーバーで同時に実行しても同じ通知は1度だけ送られる
+func (s *ReminderService) DispatchSnoozedReminders() (int, error) {
+	now := s.notificationSvc.Now()
+
+	snoozes, err := s.notificationRepo.GetDueSnoozes(now)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, snooze := range snoozes {
+		claimed, err := s.notificationRepo.ClaimSnooze(snooze.ID, now)
+		if err != nil {
+			fmt.Printf("通知スケジューラー: スヌーズID: %d の記録に失敗: %v\n", snooze.ID, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		// スヌーズした後に服用記録が登録された場合は再通知しない
+		logged, err := s.medicationRepo.HasLogSince(snooze.UserID, snooze.CreatedAt)
+		if err != nil || logged {
+			continue
+		}
+
+		user, err := s.userRepo.FindByID(snooze.UserID)
+		if err != nil {
+			continue
+		}
+		setting, err := s.notificationRepo.GetSettingByUserID(user.ID)
+		if err != nil || !setting.IsEnabled {
+			continue
+		}
+
+		slot := ReminderSlot{
+			Kind:         reminderKindSnooze,
+			Slot:         fmt.Sprintf("%d", snooze.ID),
+			MedicationID: snooze.MedicationID,
+		}
+		if err := s.notificationSvc.SendSlotNotification(*user, *setting, snoozeMessage, 0, slot); err != nil {
+			fmt.Printf("通知スケジューラー: ユーザーID: %s へのスヌーズの通知送信失敗: %v\n", user.ID, err)
+			continue
+		}
+		sent++
+	}
+
+	return sent, nil
+}
+
+// PerformAction は通知のアクションをアクショントークンで認証して実行する（セッションは不要）
+// 服用した場合は服用記録を登録し、スヌーズの場合は15分後に再通知する。トークンは1度だけ使用できる
+func (s *ReminderService) PerformAction(token, action string) error {
+	claims, err := s.notificationSvc.VerifyActionToken(token)
+	if err != nil {
+		return fmt.Errorf("invalid or expired action token")
+	}
+
+	// トークンの使用済みの記録とスヌーズ・服用記録の登録は1つのトランザクションで行う
+	// （登録に失敗した場合にトークンだけが使用済みにならないようにする）
+	now := s.notificationSvc.Now()
+	if action == NotificationActionSnooze {
+		claimed, err := s.notificationRepo.CreateSnoozeWithDispatch(&model.SnoozedReminder{
+			UserID:       claims.UserID,
+			MedicationID: claims.MedicationID,
+			RemindAt:     now.Add(SnoozeDuration),
+		}, reminderKindAction, claims.Nonce, now)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			return fmt.Errorf("action token already used")
+		}
+		return nil
+	}
+	return s.recordActionLog(claims, action == NotificationActionTakenBleeding, now)
+}
+
+// recordActionLog は通知のアクションから服用記録を登録する
+func (s *ReminderService) recordActionLog(claims *ActionClaims, hasBleeding bool, now time.Time) error {
+	medicationLog := model.MedicationLog{
+		UserID:    claims.UserID,
+		CreatedAt: now,
+	}
+	medicationLog.SetHasBleeding(hasBleeding)
+
+	// 通知後に薬が削除された場合は薬の指定なしで記録する
+	var medication *model.Medication
+	if claims.MedicationID != nil {
+		if found, err := s.medicationRepo.GetMedicationByID(claims.UserID, *claims.MedicationID); err == nil {
+			medication = found
+			medicationLog.MedicationID = claims.MedicationID
+		}
+	}
+
+	// 予定時刻のある薬の場合は服用タイミングを判定する
+	if medication != nil {
+		loc, err := s.medicationSvc.GetLocation(claims.UserID)
+		if err != nil {
+			return err
+		}
+		if intake := ClassifyIntake(*medication, now.In(loc)); intake != nil {
+			medicationLog.IntakeStatus = intake.Status
+			medicationLog.ScheduledAt = &intake.ScheduledAt
+			medicationLog.DelayMinutes = int(intake.Delay.Minutes())
+		}
+	}
+
+	claimed, err := s.medicationRepo.RegisterLogWithDispatch(claims.UserID, medicationLog, reminderKindAction, claims.Nonce)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return fmt.Errorf("action token already used")
+	}
+
+	// 薬の指定がない場合は服用中の薬が1つだけであればその薬の在庫を減らす
+	if medication == nil {
+		if medications, err := s.medicationRepo.GetMedicationsByUserID(claims.UserID); err == nil {
+			medication = DefaultMedication(medications)
+		}
+	}
+
+	// 在庫を管理している薬の場合は在庫を減らす（失敗しても服用記録は登録済みのため成功とする）
+	if medication != nil && medication.TracksInventory() {
+		// シートを開封した日はユーザーのタイムゾーンの日付とする
+		openedAt := now
+		if loc, err := s.medicationSvc.GetLocation(claims.UserID); err == nil {
+			openedAt = now.In(loc)
+		}
+		if _, err := s.medicationRepo.ConsumePill(claims.UserID, medication.ID, openedAt); err != nil {
+			fmt.Printf("在庫の更新に失敗: %v\n", err)
+		}
+	}
+
+	return nil
+}
+
+// actionMedicationID は通知のアクションで服用記録を登録する薬を返す
+// 通知時刻が服用予定時刻と一致する薬が1つだけの場合はその薬、服用中の薬が1つだけの場合はその薬とし、
+// 判断できない場合は薬の指定なしとする
+func actionMedicationID(medications []model.Medication, hhmm string) *uint {
+	var scheduled []model.Medication
+	for _, medication := range medications {
+		if medication.IsActive && medication.ScheduledTime == hhmm {
+			scheduled = append(scheduled, medication)
+		}
+	}
+
+	switch len(scheduled) {
+	case 1:
+		return &scheduled[0].ID
+	case 0:
+		if medication := DefaultMedication(medications); medication != nil {
+			return &medication.ID
+		}
+	}
+	return nil
+}
+
+// DefaultMedication は薬の指定がない記録の対象とする薬（服用中の薬が1つだけの場合はその薬）を返す
+// 判断できない場合はnilを返す
+func DefaultMedication(medications []model.Medication) *model.Medication {
+	var active []model.Medication
+	for _, medication := range medications {
+		if medication.IsActive {
+			active = append(active, medication)
+		}
+	}
+
+	if len(active) != 1 {
+		return nil
+	}
+	return &active[0]
+}
+
+// LatestSettingsByUser は通知設定をユーザーIDでマップ化する（複数ある場合は最後に更新された設定）
+func LatestSettingsByUser(settings []model.NotificationSetting) map[string]model.NotificationSetting {
+	settingsMap := make(map[string]model.NotificationSetting)
+	for _, setting := range settings {
+		existingSetting, exists := settingsMap[setting.UserID]
+		if !exists || setting.UpdatedAt.After(existingSetting.UpdatedAt) {
+			settingsMap[setting.UserID] = setting
+		}
+	}
+	return settingsMap
+}
+
+// reminderTimes は通知時刻（HH:MM、ユーザーのタイムゾーン）を早い順に返す
+// 服用中の薬の服用予定時刻を用い、予定時刻が登録されていない場合はデフォルトの通知時刻とする
+func reminderTimes(medications []model.Medication) []string {
+	seen := make(map[string]bool)
+	var times []string
+	for _, medication := range medications {
+		if !medication.IsActive || medication.ScheduledTime == "" || seen[medication.ScheduledTime] {
+			continue
+		}
+		seen[medication.ScheduledTime] = true
+		times = append(times, medication.ScheduledTime)
+	}
+
+	if len(times) == 0 {
+		return []string{model.DefaultReminderTime}
+	}
+	sort.Strings(times)
+	return times
+}
+
+// reminderSlots は前日と当日の通知時刻を返す（nowは通知時刻のタイムゾーン）
+// 日付をまたぐ直前の通知時刻も判定できるよう前日の通知時刻も含める。通知しない曜日の通知時刻は除く
+func reminderSlots(times []string, setting model.NotificationSetting, now time.Time) []time.Time {
+	today := startOfDay(now)
+	var slots []time.Time
+	for _, day := range []time.Time{today.AddDate(0, 0, -1), today} {
+		if !setting.RemindsOn(day.Weekday()) {
+			continue
+		}
+		for _, hhmm := range times {
+			t, err := time.Parse("15:04", hhmm)
+			if err != nil {
+				continue
+			}
+			slots = append(slots, time.Date(day.Year(), day.Month(), day.Day(), t.Hour(), t.Minute(), 0, 0, now.Location()))
+		}
+	}
+	return slots
+}
+
+// dueReminderSlot は通知時刻を迎えている場合に、その通知時刻を送信記録の書式で返す（nowは通知時刻のタイムゾーン）
+func dueReminderSlot(times []string, setting model.NotificationSetting, now time.Time) (string, bool) {
+	for _, slotAt := range reminderSlots(times, setting, now) {
+		elapsed := now.Sub(slotAt)
+		if elapsed >= 0 && elapsed < reminderCatchUpMinutes*time.Minute {
+			return slotAt.Format(reminderSlotLayout), true
+		}
+	}
+	return "", false
+}
+
+// dueFollowUp は直近の通知時刻から再通知の時刻を迎えている場合に、その通知時刻と何回目の再通知かを返す
+// 次の通知時刻を迎えた後は、前の通知時刻の再通知は送らない
+func dueFollowUp(times []string, setting model.NotificationSetting, now time.Time) (time.Time, int, bool) {
+	interval := time.Duration(setting.FollowUpIntervalMinutes) * time.Minute
+	if interval <= 0 || setting.FollowUpMaxCount <= 0 {
+		return time.Time{}, 0, false
+	}
+
+	var latest time.Time
+	for _, slotAt := range reminderSlots(times, setting, now) {
+		if !slotAt.After(now) && slotAt.After(latest) {
+			latest = slotAt
+		}
+	}
+	if latest.IsZero() {
+		return time.Time{}, 0, false
+	}
+
+	elapsed := now.Sub(latest)
+	attempt := int(elapsed / interval)
+	if attempt < 1 || attempt > setting.FollowUpMaxCount {
+		return time.Time{}, 0, false
+	}
+	if elapsed-time.Duration(attempt)*interval >= reminderCatchUpMinutes*time.Minute {
+		return time.Time{}, 0, false
+	}
+	return latest, attempt, true
+}
+
+// generateStatusBasedMessage はユーザーの薬のステータスに応じた通知メッセージを生成する
+func generateStatusBasedMessage(
+	status *dto.MedicationStatusResponse, regimen model.Regimen, now time.Time,
+) string {
+	if status.IsRestPeriod {
+		// 休薬期間中のメッセージ
+		if status.RestDaysLeft > 0 {
+			return fmt.Sprintf("現在休薬期間中です（%d日間）。あと%d日で服薬を再開してください。",
+				regimen.RestPeriodDays, status.RestDaysLeft)
+		} else {
+			return "休薬期間が終了しました。本日から服薬を再開してください。"
+		}
+	} else {
+		// 最大連続服用日数が近づいている場合は休薬の予告を行う
+		if status.ForcedRestDueAt != nil {
+			daysUntil := int(status.ForcedRestDueAt.Sub(now).Hours()/24) + 1
+			if daysUntil <= forcedRestWarningDays {
+				return fmt.Sprintf("最大連続服用日数（%d日）まであと%d日です。%sから休薬期間に入ります。",
+					regimen.MaxContinuousDays, daysUntil, status.ForcedRestDueAt.Format("1月2日"))
+			}
+		}
+
+		// 前日が飲み忘れとして記録されている場合
+		if status.MissedYesterday {
+			return "昨日の服用記録がありません。お薬の時間です。忘れずに服用してください。"
+		}
+
+		// 通常の服薬期間のメッセージ
+		if status.CurrentStreak > 0 {
+			return fmt.Sprintf("お薬の時間です。忘れずに服用してください。（連続%d日目）", status.CurrentStreak)
+		} else {
+			return "お薬の時間です。忘れずに服用してください。"
+		}
+	}
+}
+
+// generateRefillReminder は残り錠数が少なくなっている服用中の薬の補充を促すメッセージを生成する
+func generateRefillReminder(medications []model.Medication) string {
+	var reminders []string
+	for _, medication := range medications {
+		if !medication.IsActive || !medication.NeedsRefill() {
+			continue
+		}
+		if medication.RemainingPills() == 0 {
+			reminders = append(reminders, fmt.Sprintf("「%s」の在庫がありません。", medication.Name))
+			continue
+		}
+		reminders = append(reminders, fmt.Sprintf("「%s」の残りが%d錠です。", medication.Name, medication.RemainingPills()))
+	}
+
+	if len(reminders) == 0 {
+		return ""
+	}
+	return strings.Join(reminders, "") + "新しいシートを用意してください。"
+}
+
+// generatePrescriptionAlert は有効期限が近い、またはリフィル回数を使い切った処方箋について通知するメッセージを生成する
+// 同じ薬の処方箋が複数ある場合は発行日が最も新しいものだけを対象とする
+func generatePrescriptionAlert(
+	prescriptions []model.Prescription, medications []model.Medication, now time.Time,
+) string {
+	names := make(map[uint]string)
+	for _, medication := range medications {
+		names[medication.ID] = medication.Name
+	}
+
+	latest := make(map[uint]model.Prescription)
+	var unlinked []model.Prescription
+	for _, prescription := range prescriptions {
+		if prescription.MedicationID == nil {
+			unlinked = append(unlinked, prescription)
+			continue
+		}
+		existing, exists := latest[*prescription.MedicationID]
+		if !exists || prescription.IssuedOn.After(existing.IssuedOn) {
+			latest[*prescription.MedicationID] = prescription
+		}
+	}
+
+	targets := unlinked
+	for _, prescription := range prescriptions {
+		if prescription.MedicationID != nil && latest[*prescription.MedicationID].ID == prescription.ID {
+			targets = append(targets, prescription)
+		}
+	}
+
+	var alerts []string
+	for _, prescription := range targets {
+		label := "処方箋"
+		if prescription.MedicationID != nil {
+			if name, ok := names[*prescription.MedicationID]; ok {
+				label = fmt.Sprintf("「%s」の処方箋", name)
+			}
+		}
+
+		// 有効期限は暦日として扱い、ユーザーのタイムゾーンで残り日数を数える
+		expires := prescription.ExpiresOn.In(now.Location())
+		daysLeft := daysBetween(now, expires)
+
+		switch {
+		case daysLeft < 0:
+			// 期限切れから時間が経った処方箋は通知し続けない
+			if daysLeft >= -prescriptionExpiryWarningDays {
+				alerts = append(alerts, fmt.Sprintf("%sの有効期限が切れています。", label))
+			}
+		case daysLeft == 0:
+			alerts = append(alerts, fmt.Sprintf("%sの有効期限は本日までです。", label))
+		case daysLeft <= prescriptionExpiryWarningDays:
+			alerts = append(alerts, fmt.Sprintf("%sの有効期限まであと%d日です。", label, daysLeft))
+		case prescription.RefillsLeft == 0:
+			alerts = append(alerts, fmt.Sprintf("%sのリフィル回数が残っていません。", label))
+		}
+	}
+
+	if len(alerts) == 0 {
+		return ""
+	}
+	return strings.Join(alerts, "") + "早めに受診してください。"
+}
@@ -0,0 +1,315 @@
+package service
+
+import (
+	"database/sql/driver"
+	"errors"
+	"okusuri-backend/internal/model"
+	"okusuri-backend/internal/repository"
+	"okusuri-backend/internal/testutil"
+	"okusuri-backend/pkg/clock"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 在庫の補充を促すメッセージのテスト
+func TestGenerateRefillReminder(t *testing.T) {
+	t.Run("残り錠数が閾値を下回った服用中の薬のみ通知される", func(t *testing.T) {
+		medications := []model.Medication{
+			{Name: "ピル", IsActive: true, PillsPerPack: 28, CurrentPackPillsLeft: 5, RefillThresholdPills: 7},
+			{Name: "鉄剤", IsActive: true, PillsPerPack: 30, CurrentPackPillsLeft: 5, PacksOnHand: 1, RefillThresholdPills: 7},
+			{Name: "休止中の薬", IsActive: false, PillsPerPack: 28, RefillThresholdPills: 7},
+			{Name: "在庫管理なし", IsActive: true, RefillThresholdPills: 7},
+		}
+
+		message := generateRefillReminder(medications)
+		assert.Equal(t, "「ピル」の残りが5錠です。新しいシートを用意してください。", message)
+	})
+
+	t.Run("補充が必要な薬がない場合は空文字", func(t *testing.T) {
+		assert.Empty(t, generateRefillReminder(nil))
+	})
+}
+
+// 処方箋の有効期限・リフィル回数の通知のテスト
+func TestGeneratePrescriptionAlert(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+	now := time.Date(2025, 6, 1, 21, 0, 0, 0, tokyo)
+	medications := []model.Medication{{ID: 1, Name: "ピル"}, {ID: 2, Name: "鉄剤"}}
+	pill, iron := uint(1), uint(2)
+
+	t.Run("有効期限が2週間以内の処方箋を通知する", func(t *testing.T) {
+		prescriptions := []model.Prescription{{
+			ID: 1, MedicationID: &pill, RefillsLeft: 2,
+			IssuedOn:  model.NewDate(2025, 3, 1),
+			ExpiresOn: model.NewDate(2025, 6, 11),
+		}}
+
+		message := generatePrescriptionAlert(prescriptions, medications, now)
+		assert.Equal(t, "「ピル」の処方箋の有効期限まであと10日です。早めに受診してください。", message)
+	})
+
+	t.Run("タイムゾーンに関わらず有効期限の暦日で数える", func(t *testing.T) {
+		prescriptions := []model.Prescription{{
+			ID: 1, MedicationID: &pill, RefillsLeft: 2,
+			IssuedOn:  model.NewDate(2025, 3, 1),
+			ExpiresOn: model.NewDate(2025, 6, 11),
+		}}
+
+		newYork, err := time.LoadLocation("America/New_York")
+		require.NoError(t, err)
+		message := generatePrescriptionAlert(prescriptions, medications, time.Date(2025, 6, 1, 21, 0, 0, 0, newYork))
+		assert.Equal(t, "「ピル」の処方箋の有効期限まであと10日です。早めに受診してください。", message)
+	})
+
+	t.Run("リフィル回数が0の場合は有効期限に関わらず通知する", func(t *testing.T) {
+		prescriptions := []model.Prescription{{
+			ID: 1, MedicationID: &iron, RefillsLeft: 0,
+			IssuedOn:  model.NewDate(2025, 5, 1),
+			ExpiresOn: model.NewDate(2025, 11, 1),
+		}}
+
+		message := generatePrescriptionAlert(prescriptions, medications, now)
+		assert.Equal(t, "「鉄剤」の処方箋のリフィル回数が残っていません。早めに受診してください。", message)
+	})
+
+	t.Run("新しい処方箋がある場合は古い処方箋を通知しない", func(t *testing.T) {
+		prescriptions := []model.Prescription{
+			{
+				ID: 2, MedicationID: &pill, RefillsLeft: 3,
+				IssuedOn:  model.NewDate(2025, 5, 20),
+				ExpiresOn: model.NewDate(2025, 12, 1),
+			},
+			{
+				ID: 1, MedicationID: &pill, RefillsLeft: 0,
+				IssuedOn:  model.NewDate(2025, 1, 1),
+				ExpiresOn: model.NewDate(2025, 6, 5),
+			},
+		}
+
+		assert.Empty(t, generatePrescriptionAlert(prescriptions, medications, now))
+	})
+}
+
+// 通知時刻のテスト
+func TestReminderTimes(t *testing.T) {
+	t.Run("服用中の薬の服用予定時刻を重複なく早い順に返す", func(t *testing.T) {
+		medications := []model.Medication{
+			{IsActive: true, ScheduledTime: "21:00"},
+			{IsActive: true, ScheduledTime: "08:30"},
+			{IsActive: true, ScheduledTime: "21:00"},
+			{IsActive: false, ScheduledTime: "12:00"},
+		}
+		assert.Equal(t, []string{"08:30", "21:00"}, reminderTimes(medications))
+	})
+
+	t.Run("服用予定時刻がない場合はデフォルトの通知時刻", func(t *testing.T) {
+		assert.Equal(t, []string{model.DefaultReminderTime}, reminderTimes([]model.Medication{{IsActive: true}}))
+	})
+}
+
+// 通知時刻を迎えたかどうかの判定のテスト
+func TestDueReminderSlot(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+
+	t.Run("通知時刻ちょうどに通知する", func(t *testing.T) {
+		slot, due := dueReminderSlot([]string{"21:00"}, model.NotificationSetting{}, time.Date(2025, 6, 10, 21, 0, 0, 0, tokyo))
+		assert.True(t, due)
+		assert.Equal(t, "2025-06-10T21:00", slot)
+	})
+
+	t.Run("通知時刻の前や取りこぼしを補う時間を過ぎた場合は通知しない", func(t *testing.T) {
+		_, due := dueReminderSlot([]string{"21:00"}, model.NotificationSetting{}, time.Date(2025, 6, 10, 20, 59, 0, 0, tokyo))
+		assert.False(t, due)
+
+		_, due = dueReminderSlot([]string{"21:00"}, model.NotificationSetting{}, time.Date(2025, 6, 10, 21, reminderCatchUpMinutes, 0, 0, tokyo))
+		assert.False(t, due)
+	})
+
+	t.Run("日付をまたいでも前日の通知時刻として判定する", func(t *testing.T) {
+		slot, due := dueReminderSlot([]string{"23:55"}, model.NotificationSetting{}, time.Date(2025, 6, 11, 0, 3, 0, 0, tokyo))
+		assert.True(t, due)
+		assert.Equal(t, "2025-06-10T23:55", slot)
+	})
+	t.Run("通知しない曜日は通知しない", func(t *testing.T) {
+		// 2025-06-10は火曜日
+		setting := model.NotificationSetting{ReminderWeekdays: model.StringList{model.WeekdayMonday}}
+		_, due := dueReminderSlot([]string{"21:00"}, setting, time.Date(2025, 6, 10, 21, 0, 0, 0, tokyo))
+		assert.False(t, due)
+
+		setting.ReminderWeekdays = append(setting.ReminderWeekdays, model.WeekdayTuesday)
+		_, due = dueReminderSlot([]string{"21:00"}, setting, time.Date(2025, 6, 10, 21, 0, 0, 0, tokyo))
+		assert.True(t, due)
+	})
+
+	t.Run("日付をまたいだ場合は前日の曜日で判定する", func(t *testing.T) {
+		setting := model.NotificationSetting{ReminderWeekdays: model.StringList{model.WeekdayTuesday}}
+		slot, due := dueReminderSlot([]string{"23:55"}, setting, time.Date(2025, 6, 11, 0, 3, 0, 0, tokyo))
+		assert.True(t, due)
+		assert.Equal(t, "2025-06-10T23:55", slot)
+	})
+}
+
+// 再通知の時刻の判定のテスト
+func TestDueFollowUp(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+	setting := model.NotificationSetting{FollowUpIntervalMinutes: 30, FollowUpMaxCount: 2}
+	slotAt := time.Date(2025, 6, 10, 21, 0, 0, 0, tokyo)
+
+	t.Run("通知時刻から間隔ごとに最大回数まで再通知する", func(t *testing.T) {
+		_, _, due := dueFollowUp([]string{"21:00"}, setting, slotAt.Add(29*time.Minute))
+		assert.False(t, due)
+
+		at, attempt, due := dueFollowUp([]string{"21:00"}, setting, slotAt.Add(30*time.Minute))
+		assert.True(t, due)
+		assert.Equal(t, slotAt, at)
+		assert.Equal(t, 1, attempt)
+
+		_, attempt, due = dueFollowUp([]string{"21:00"}, setting, slotAt.Add(62*time.Minute))
+		assert.True(t, due)
+		assert.Equal(t, 2, attempt)
+
+		_, _, due = dueFollowUp([]string{"21:00"}, setting, slotAt.Add(90*time.Minute))
+		assert.False(t, due)
+	})
+
+	t.Run("次の通知時刻を迎えた後は前の通知時刻の再通知をしない", func(t *testing.T) {
+		at, attempt, due := dueFollowUp([]string{"21:00", "21:45"}, setting, slotAt.Add(60*time.Minute))
+		assert.False(t, due)
+		assert.Zero(t, attempt)
+		assert.True(t, at.IsZero())
+	})
+
+	t.Run("再通知の回数が0の場合は再通知しない", func(t *testing.T) {
+		_, _, due := dueFollowUp([]string{"21:00"}, model.NotificationSetting{FollowUpIntervalMinutes: 30}, slotAt.Add(30*time.Minute))
+		assert.False(t, due)
+	})
+}
+
+// 通知のアクションで服用記録を登録する薬の判定のテスト
+func TestActionMedicationID(t *testing.T) {
+	t.Run("通知時刻と服用予定時刻が一致する薬を返す", func(t *testing.T) {
+		medications := []model.Medication{
+			{ID: 1, IsActive: true, ScheduledTime: "08:00"},
+			{ID: 2, IsActive: true, ScheduledTime: "21:00"},
+		}
+		assert.Equal(t, uint(2), *actionMedicationID(medications, "21:00"))
+	})
+
+	t.Run("服用中の薬が1つだけの場合はその薬を返す", func(t *testing.T) {
+		medications := []model.Medication{{ID: 1, IsActive: true}, {ID: 2, IsActive: false}}
+		assert.Equal(t, uint(1), *actionMedicationID(medications, "21:00"))
+	})
+
+	t.Run("判断できない場合は薬の指定なし", func(t *testing.T) {
+		medications := []model.Medication{{ID: 1, IsActive: true}, {ID: 2, IsActive: true}}
+		assert.Nil(t, actionMedicationID(medications, "21:00"))
+	})
+}
+
+// 通知のアクションの実行（トークンの使用済みの記録と登録を1つのトランザクションで行う）のテスト
+func TestReminderService_PerformAction(t *testing.T) {
+	newService := func() (*ReminderService, *NotificationService) {
+		notificationSvc := NewNotificationService(clock.NewFixed(time.Date(2025, 6, 10, 21, 0, 0, 0, time.UTC)))
+		notificationSvc.actionSecret = []byte("test-secret")
+		reminderSvc := NewReminderService(nil, repository.NewNotificationRepository(),
+			repository.NewMedicationRepository(), nil, nil, notificationSvc)
+		return reminderSvc, notificationSvc
+	}
+	claimDispatch := func(db *testutil.FakeDB) {
+		db.OnQuery(`INSERT INTO "notification_dispatches"`, []string{"id"}, []driver.Value{int64(1)})
+	}
+
+	t.Run("服用のアクションは使用済みの記録と服用記録を同じトランザクションで登録する", func(t *testing.T) {
+		db := testutil.NewFakeDB(t)
+		claimDispatch(db)
+		service, notificationSvc := newService()
+		token, err := notificationSvc.IssueActionToken("test-user", nil)
+		require.NoError(t, err)
+
+		require.NoError(t, service.PerformAction(token, NotificationActionTaken))
+
+		statements := db.Statements()
+		begin := indexOfStatement(statements, "BEGIN")
+		claim := indexOfStatement(statements, `INSERT INTO "notification_dispatches"`)
+		log := indexOfStatement(statements, `INSERT INTO "medication_logs"`)
+		commit := indexOfStatement(statements, "COMMIT")
+		assert.True(t, begin < claim && claim < log && log < commit, statements)
+	})
+
+	t.Run("使用済みのトークンでは服用記録を登録しない", func(t *testing.T) {
+		db := testutil.NewFakeDB(t)
+		service, notificationSvc := newService()
+		token, err := notificationSvc.IssueActionToken("test-user", nil)
+		require.NoError(t, err)
+
+		err = service.PerformAction(token, NotificationActionTaken)
+		assert.EqualError(t, err, "action token already used")
+		assert.False(t, db.Executed(`INSERT INTO "medication_logs"`))
+	})
+
+	t.Run("服用記録の登録に失敗した場合はトークンを使用済みにしない", func(t *testing.T) {
+		db := testutil.NewFakeDB(t)
+		claimDispatch(db)
+		db.OnError(`INSERT INTO "medication_logs"`, errors.New("insert failed"))
+		service, notificationSvc := newService()
+		token, err := notificationSvc.IssueActionToken("test-user", nil)
+		require.NoError(t, err)
+
+		assert.Error(t, service.PerformAction(token, NotificationActionTaken))
+		assert.True(t, db.Executed("ROLLBACK"))
+		assert.False(t, db.Executed("COMMIT"))
+	})
+
+	t.Run("スヌーズは使用済みの記録とスヌーズを同じトランザクションで登録する", func(t *testing.T) {
+		db := testutil.NewFakeDB(t)
+		claimDispatch(db)
+		service, notificationSvc := newService()
+		token, err := notificationSvc.IssueActionToken("test-user", nil)
+		require.NoError(t, err)
+
+		require.NoError(t, service.PerformAction(token, NotificationActionSnooze))
+
+		statements := db.Statements()
+		claim := indexOfStatement(statements, `INSERT INTO "notification_dispatches"`)
+		snooze := indexOfStatement(statements, `INSERT INTO "snoozed_reminders"`)
+		commit := indexOfStatement(statements, "COMMIT")
+		assert.True(t, claim >= 0 && claim < snooze && snooze < commit, statements)
+	})
+
+	t.Run("使用済みのトークンではスヌーズしない", func(t *testing.T) {
+		db := testutil.NewFakeDB(t)
+		service, notificationSvc := newService()
+		token, err := notificationSvc.IssueActionToken("test-user", nil)
+		require.NoError(t, err)
+
+		err = service.PerformAction(token, NotificationActionSnooze)
+		assert.EqualError(t, err, "action token already used")
+		assert.False(t, db.Executed(`INSERT INTO "snoozed_reminders"`))
+	})
+
+	t.Run("不正なトークンはエラー", func(t *testing.T) {
+		db := testutil.NewFakeDB(t)
+		service, _ := newService()
+
+		err := service.PerformAction("invalid-token", NotificationActionTaken)
+		assert.EqualError(t, err, "invalid or expired action token")
+		assert.Empty(t, db.Statements())
+	})
+}
+
+// indexOfStatement は指定した文字列を含む最初のSQLの位置を返す（ない場合は-1）
+func indexOfStatement(statements []string, contains string) int {
+	for i, statement := range statements {
+		if strings.Contains(statement, contains) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"okusuri-backend/internal/dto"
+	"okusuri-backend/internal/model"
+	"okusuri-backend/internal/repository"
+	"time"
+)
+
+// statsPeriodDays は統計の集計期間ごとの日数
+var statsPeriodDays = map[string]int{
+	dto.StatsPeriod30Days: 30,
+	dto.StatsPeriod90Days: 90,
+	dto.StatsPeriod1Year:  365,
+}
+
+// cachedStats はキャッシュした統計と、集計時点の服薬履歴のバージョン
+type cachedStats struct {
+	key   string
+	stats *dto.MedicationStatsResponse
+}
+
+// IsValidStatsPeriod は統計の集計期間として有効かどうかを判定する
+func IsValidStatsPeriod(period string) bool {
+	_, ok := statsPeriodDays[period]
+	return ok
+}
+
+// GetMedicationStats は指定期間の服薬統計を返す
+//
+// 集計結果はユーザーごとにキャッシュし、日付と服薬履歴のバージョン（記録の件数と最終更新日時）が変わるまで再利用する。
+// バージョンは毎回データベースから取得するため、他のサーバーで記録が登録・更新・削除・復元された場合も再計算される。
+func (s *MedicationService) GetMedicationStats(userID, period string) (*dto.MedicationStatsResponse, error) {
+	// ユーザーのタイムゾーンを取得
+	loc, err := s.GetLocation(userID)
+	if err != nil {
+		return nil, err
+	}
+	now := s.clock.Now().In(loc)
+	today := startOfDay(now)
+
+	version, err := s.medicationRepo.GetHistoryVersion(userID)
+	if err != nil {
+		return nil, err
+	}
+	key := statsCacheKey(today, version)
+	if stats := s.cachedStats(userID, period, key); stats != nil {
+		return stats, nil
+	}
+
+	history, err := s.loadMedicationHistory(userID, now)
+	if err != nil {
+		return nil, err
+	}
+	now = now.In(history.loc)
+
+	// 休薬期間はレジメンに従う薬の服用記録から判定する
+	regimenLogs := takenLogs(filterRegimenLogs(history.logs, history.medications))
+	rests := s.strategyFor(history.regimen).RestPeriods(regimenLogs, history.regimen, now, now)
+
+	periodStart := today.AddDate(0, 0, -(statsPeriodDays[period] - 1))
+	stats := s.calculateStats(history.logs, regimenLogs, rests, periodStart, today)
+	stats.Period = period
+
+	s.storeStats(userID, period, key, stats)
+	return stats, nil
+}
+
+// statsCacheKey は集計日（ユーザーのタイムゾーン）と服薬履歴のバージョンからキャッシュのキーを作成する
+func statsCacheKey(today time.Time, version *repository.HistoryVersion) string {
+	formatTime := func(t *time.Time) string {
+		if t == nil {
+			return "-"
+		}
+		return t.UTC().Format(time.RFC3339Nano)
+	}
+	return fmt.Sprintf("%s %s/%d/%s/%s/%s",
+		today.Format("2006-01-02"), today.Location(), version.LogCount,
+		formatTime(version.LogsUpdatedAt), formatTime(version.MedicationsUpdatedAt), formatTime(version.RegimenUpdatedAt))
+}
+
+// cachedStats は同じキーで集計したキャッシュがあれば返す
+func (s *MedicationService) cachedStats(userID, period, key string) *dto.MedicationStatsResponse {
+	s.statsMutex.Lock()
+	defer s.statsMutex.Unlock()
+
+	cached, exists := s.statsCache[userID][period]
+	if !exists || cached.key != key {
+		return nil
+	}
+	return cached.stats
+}
+
+// storeStats は集計結果をキャッシュする
+func (s *MedicationService) storeStats(userID, period, key string, stats *dto.MedicationStatsResponse) {
+	s.statsMutex.Lock()
+	defer s.statsMutex.Unlock()
+
+	if s.statsCache == nil {
+		s.statsCache = make(map[string]map[string]cachedStats)
+	}
+	if s.statsCache[userID] == nil {
+		s.statsCache[userID] = make(map[string]cachedStats)
+	}
+	s.statsCache[userID][period] = cachedStats{key: key, stats: stats}
+}
+
+// calculateStats は集計期間内の服用記録と休薬期間から統計を計算する
+//
+// logsは飲み忘れの自動記録を含む全てのログ、regimenLogsはレジメンに従う薬の服用記録（いずれも新しい順）。
+func (s *MedicationService) calculateStats(
+	logs, regimenLogs []model.MedicationLog, rests []restPeriod, periodStart, today time.Time,
+) *dto.MedicationStatsResponse {
+	stats := &dto.MedicationStatsResponse{
+		From: periodStart.Format("2006-01-02"),
+		To:   today.Format("2006-01-02"),
+	}
+
+	inPeriod := func(day time.Time) bool {
+		return !day.Before(periodStart) && !day.After(today)
+	}
+	isRestDay := func(day time.Time) bool {
+		for _, rest := range rests {
+			if !day.Before(rest.Start) && !day.After(rest.End) {
+				return true
+			}
+		}
+		return false
+	}
+
+	// 服用した日（休薬期間中の記録は服用率に含めない）と最長連続服用日数
+	takenDays := make(map[string]bool)
+	var prevDay time.Time
+	streak := 0
+	dates := s.extractUniqueDates(regimenLogs)
+	for i := len(dates) - 1; i >= 0; i-- {
+		day := startOfDay(dates[i])
+		if !inPeriod(day) {
+			continue
+		}
+		if !isRestDay(day) {
+			takenDays[day.Format("2006-01-02")] = true
+		}
+
+		if !prevDay.IsZero() && daysBetween(prevDay, day) == 1 {
+			streak++
+		} else {
+			streak = 1
+		}
+		prevDay = day
+		if streak > stats.LongestStreak {
+			stats.LongestStreak = streak
+		}
+	}
+	stats.TakenDays = len(takenDays)
+
+	// 服用すべき日は最初の記録以降の休薬期間外の日（今日は服用済みの場合のみ含める）
+	if len(dates) > 0 {
+		expectedStart := periodStart
+		if first := startOfDay(dates[len(dates)-1]); first.After(expectedStart) {
+			expectedStart = first
+		}
+		expectedEnd := today
+		if !takenDays[today.Format("2006-01-02")] {
+			expectedEnd = today.AddDate(0, 0, -1)
+		}
+		for day := expectedStart; !day.After(expectedEnd); day = day.AddDate(0, 0, 1) {
+			if !isRestDay(day) {
+				stats.ExpectedDays++
+			}
+		}
+	}
+	if stats.ExpectedDays > 0 {
+		stats.AdherenceRate = roundTo(float64(stats.TakenDays)/float64(stats.ExpectedDays)*100, 1)
+	}
+
+	// 休薬期間の回数と平均周期日数（休薬開始から次の休薬開始まで）
+	var cycleDays []int
+	for i, rest := range rests {
+		if !inPeriod(rest.Start) {
+			continue
+		}
+		stats.RestPeriodCount++
+		if i > 0 {
+			cycleDays = append(cycleDays, daysBetween(rests[i-1].Start, rest.Start))
+		}
+	}
+	if len(cycleDays) > 0 {
+		total := 0
+		for _, days := range cycleDays {
+			total += days
+		}
+		stats.AverageCycleLength = roundTo(float64(total)/float64(len(cycleDays)), 1)
+	}
+
+	// 1周期あたりの平均出血日数
+	bleedingDays := 0
+	for _, day := range s.groupLogsByDay(regimenLogs) {
+		if day.hasBleeding && inPeriod(day.date) {
+			bleedingDays++
+		}
+	}
+	if stats.RestPeriodCount > 0 {
+		stats.AverageBleedingDaysPerCycle = roundTo(float64(bleedingDays)/float64(stats.RestPeriodCount), 1)
+	}
+
+	// 飲み忘れの回数
+	for _, log := range logs {
+		if log.IsMissed && inPeriod(startOfDay(log.CreatedAt)) {
+			stats.MissedDoseCount++
+		}
+	}
+
+	return stats
+}
+
+// roundTo は指定した小数点以下の桁数で四捨五入する
+func roundTo(value float64, digits int) float64 {
+	pow := math.Pow(10, float64(digits))
+	return math.Round(value*pow) / pow
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+	"time"
+
+	"okusuri-backend/internal/dto"
+	"okusuri-backend/internal/model"
+	"okusuri-backend/internal/repository"
+	"okusuri-backend/internal/testutil"
+	"okusuri-backend/pkg/clock"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMedicationService_CalculateStats(t *testing.T) {
+	service := &MedicationService{}
+	day := func(d int) time.Time {
+		return time.Date(2025, 3, d, 0, 0, 0, 0, time.UTC)
+	}
+
+	t.Run("休薬期間を除いた服用率と最長連続服用日数が計算される", func(t *testing.T) {
+		// 3/1〜3/10に服用し、3/8〜3/12は休薬、3/13〜3/20に服用（3/16は飲み忘れ）
+		var logs []model.MedicationLog
+		for d := 20; d >= 1; d-- {
+			if d == 11 || d == 12 || d == 16 {
+				continue
+			}
+			logs = append(logs, model.MedicationLog{CreatedAt: day(d).Add(8 * time.Hour), HasBleeding: d >= 8 && d <= 10})
+		}
+		missed := model.MedicationLog{CreatedAt: day(16).Add(23*time.Hour + 59*time.Minute), IsMissed: true}
+		allLogs := append([]model.MedicationLog{missed}, logs...)
+		rests := []restPeriod{{Start: day(8), End: day(12)}}
+
+		stats := service.calculateStats(allLogs, logs, rests, day(1), day(20))
+
+		assert.Equal(t, "2025-03-01", stats.From)
+		assert.Equal(t, "2025-03-20", stats.To)
+		assert.Equal(t, 15, stats.ExpectedDays)
+		assert.Equal(t, 14, stats.TakenDays)
+		assert.Equal(t, 93.3, stats.AdherenceRate)
+		assert.Equal(t, 10, stats.LongestStreak)
+		assert.Equal(t, 1, stats.RestPeriodCount)
+		assert.Equal(t, 3.0, stats.AverageBleedingDaysPerCycle)
+		assert.Equal(t, 1, stats.MissedDoseCount)
+	})
+
+	t.Run("休薬開始日の間隔から平均周期日数が計算される", func(t *testing.T) {
+		rests := []restPeriod{
+			{Start: day(1), End: day(5)},
+			{Start: day(15), End: day(19)},
+			{Start: day(31), End: day(31).AddDate(0, 0, 4)},
+		}
+
+		stats := service.calculateStats(nil, nil, rests, day(10), day(31))
+
+		assert.Equal(t, 2, stats.RestPeriodCount)
+		assert.Equal(t, 15.0, stats.AverageCycleLength)
+		assert.Equal(t, 0.0, stats.AdherenceRate)
+	})
+}
+
+func TestMedicationService_GetMedicationStats_Cache(t *testing.T) {
+	now := time.Date(2025, 3, 20, 12, 0, 0, 0, time.UTC)
+	updatedAt := now.Add(-time.Hour)
+
+	// newService はテスト用のデータベースを使う服薬サービスを作成する
+	newService := func(t *testing.T) (*MedicationService, *testutil.FakeDB) {
+		db := testutil.NewFakeDB(t)
+		db.OnQuery(`FROM "user"`, []string{"id", "timezone"}, []driver.Value{"test-user", "Asia/Tokyo"})
+		db.OnQuery(`AS log_count`, []string{"log_count", "logs_updated_at"}, []driver.Value{int64(1), updatedAt})
+		service := NewMedicationService(repository.NewMedicationRepository(), repository.NewRegimenRepository(),
+			repository.NewUserRepository(), clock.NewFixed(now))
+		return service, db
+	}
+	// countLogLoads は服薬記録の全件取得の回数を数える
+	countLogLoads := func(db *testutil.FakeDB) int {
+		count := 0
+		for _, statement := range db.Statements() {
+			if strings.Contains(statement, `SELECT * FROM "medication_logs"`) {
+				count++
+			}
+		}
+		return count
+	}
+
+	t.Run("服薬履歴が変わっていなければ2回目はキャッシュから返す", func(t *testing.T) {
+		service, db := newService(t)
+
+		first, err := service.GetMedicationStats("test-user", dto.StatsPeriod30Days)
+		require.NoError(t, err)
+		second, err := service.GetMedicationStats("test-user", dto.StatsPeriod30Days)
+		require.NoError(t, err)
+
+		assert.Same(t, first, second)
+		assert.Equal(t, 1, countLogLoads(db))
+	})
+
+	t.Run("服薬記録が変更されると再計算する", func(t *testing.T) {
+		service, db := newService(t)
+
+		_, err := service.GetMedicationStats("test-user", dto.StatsPeriod30Days)
+		require.NoError(t, err)
+
+		// 他のサーバーで記録が削除され、件数と最終更新日時が変わった
+		db.OnQuery(`AS log_count`, []string{"log_count", "logs_updated_at"}, []driver.Value{int64(0), now})
+		_, err = service.GetMedicationStats("test-user", dto.StatsPeriod30Days)
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, countLogLoads(db))
+	})
+
+	t.Run("件数が同じでも記録が更新・復元されると再計算する", func(t *testing.T) {
+		service, db := newService(t)
+
+		_, err := service.GetMedicationStats("test-user", dto.StatsPeriod30Days)
+		require.NoError(t, err)
+
+		db.OnQuery(`AS log_count`, []string{"log_count", "logs_updated_at"}, []driver.Value{int64(1), now})
+		_, err = service.GetMedicationStats("test-user", dto.StatsPeriod30Days)
+		require.NoError(t, err)
+
+		assert.Equal(t, 2, countLogLoads(db))
+	})
+}
@@ -0,0 +1,194 @@
+package testutil
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"okusuri-backend/pkg/config"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// FakeDB はテスト用のデータベース（SQLに含まれる文字列ごとに結果を登録する）
+// 結果が登録されていない検索は0件、更新は0行を返す
+type FakeDB struct {
+	mu         sync.Mutex
+	results    []fakeResult
+	statements []string
+}
+
+type fakeResult struct {
+	contains     string
+	columns      []string
+	rows         [][]driver.Value
+	rowsAffected int64
+	err          error
+}
+
+// NewFakeDB はテスト用のデータベースをconfig.DBに設定する（テスト終了時に元に戻す）
+func NewFakeDB(t *testing.T) *FakeDB {
+	t.Helper()
+
+	fake := &FakeDB{}
+	sqlDB := sql.OpenDB(fakeConnector{db: fake})
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("テスト用のデータベースの作成に失敗: %v", err)
+	}
+
+	original := config.DB
+	config.DB = db
+	t.Cleanup(func() {
+		config.DB = original
+		sqlDB.Close()
+	})
+
+	return fake
+}
+
+// OnQuery は指定した文字列を含む検索（RETURNINGを含む登録を含む）の結果を登録する
+func (f *FakeDB) OnQuery(contains string, columns []string, rows ...[]driver.Value) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.results = append(f.results, fakeResult{contains: contains, columns: columns, rows: rows})
+}
+
+// OnExec は指定した文字列を含む更新の影響行数を登録する
+func (f *FakeDB) OnExec(contains string, rowsAffected int64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.results = append(f.results, fakeResult{contains: contains, rowsAffected: rowsAffected})
+}
+
+// OnError は指定した文字列を含むSQLの実行をエラーにする
+func (f *FakeDB) OnError(contains string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.results = append(f.results, fakeResult{contains: contains, err: err})
+}
+
+// Statements は実行されたSQL（BEGIN・COMMIT・ROLLBACKを含む）を実行順に返す
+func (f *FakeDB) Statements() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.statements...)
+}
+
+// Executed は指定した文字列を含むSQLが実行されたかどうかを判定する
+func (f *FakeDB) Executed(contains string) bool {
+	for _, statement := range f.Statements() {
+		if strings.Contains(statement, contains) {
+			return true
+		}
+	}
+	return false
+}
+
+// result はSQLを記録し、後から登録された結果を優先して返す
+func (f *FakeDB) result(query string) fakeResult {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.statements = append(f.statements, query)
+	for i := len(f.results) - 1; i >= 0; i-- {
+		if strings.Contains(query, f.results[i].contains) {
+			return f.results[i]
+		}
+	}
+	return fakeResult{}
+}
+
+type fakeConnector struct {
+	db *FakeDB
+}
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) {
+	return &fakeConn{db: c.db}, nil
+}
+
+func (c fakeConnector) Driver() driver.Driver {
+	return fakeDriver{}
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) {
+	return nil, driver.ErrSkip
+}
+
+type fakeConn struct {
+	db *FakeDB
+}
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) {
+	return nil, driver.ErrSkip
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	c.db.result("BEGIN")
+	return fakeTx{db: c.db}, nil
+}
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	result := c.db.result(query)
+	if result.err != nil {
+		return nil, result.err
+	}
+	return driver.RowsAffected(result.rowsAffected), nil
+}
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	result := c.db.result(query)
+	if result.err != nil {
+		return nil, result.err
+	}
+	return &fakeRows{columns: result.columns, rows: result.rows}, nil
+}
+
+type fakeTx struct {
+	db *FakeDB
+}
+
+func (tx fakeTx) Commit() error {
+	tx.db.result("COMMIT")
+	return nil
+}
+
+func (tx fakeTx) Rollback() error {
+	tx.db.result("ROLLBACK")
+	return nil
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+	next    int
+}
+
+func (r *fakeRows) Columns() []string {
+	return r.columns
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.next])
+	r.next++
+	return nil
+}
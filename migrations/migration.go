@@ -1,8 +1,10 @@
 package migrations
 
 import (
+	"fmt"
 	"log"
 	"okusuri-backend/internal/model"
+	"strings"
 
 	"gorm.io/gorm"
 )
@@ -11,18 +13,93 @@ import (
 func RunMigrations(db *gorm.DB) {
 	log.Println("マイグレーションを実行します...")
 
+	// 日時として保存していた暦日の列は、AutoMigrateの前にユーザーのタイムゾーンでの日付に変換する
+	if err := migrateDateColumns(db); err != nil {
+		log.Fatalf("暦日の列の移行に失敗しました: %v", err)
+	}
+
 	// マイグレーション対象のモデルをここに追加
 	err := db.AutoMigrate(
 		&model.User{},
 		&model.Session{},
 		&model.Account{},
 		&model.Verification{},
-		&model.NotificationSetting{}, 
+		&model.NotificationSetting{},
+		&model.NotificationDispatch{},
+		&model.SnoozedReminder{},
+		&model.Medication{},
 		&model.MedicationLog{},
+		&model.Regimen{},
+		&model.HealthEntry{},
+		&model.Prescription{},
+		&model.Appointment{},
 	)
 	if err != nil {
 		log.Fatalf("マイグレーションに失敗しました: %v", err)
 	}
 
+	// 出血の程度の導入前に出血ありとして記録されたログは少量の出血として扱う
+	err = db.Unscoped().Model(&model.MedicationLog{}).
+		Where("has_bleeding = ? AND bleeding_level = ?", true, model.BleedingLevelNone).
+		Update("bleeding_level", model.BleedingLevelLight).Error
+	if err != nil {
+		log.Fatalf("出血の程度の移行に失敗しました: %v", err)
+	}
+
 	log.Println("マイグレーションが正常に完了しました")
 }
+
+// dateColumns は日時からdate型に変更した暦日の列
+var dateColumns = []struct {
+	table  string
+	column string
+}{
+	{"regimens", "pack_start_date"},
+	{"medications", "current_pack_start_date"},
+	{"prescriptions", "issued_on"},
+	{"prescriptions", "expires_on"},
+}
+
+// migrateDateColumns は日時型の暦日の列を、ユーザーのタイムゾーンでの日付としてdate型に変換する
+func migrateDateColumns(db *gorm.DB) error {
+	for _, target := range dateColumns {
+		if !db.Migrator().HasTable(target.table) || !db.Migrator().HasColumn(target.table, target.column) {
+			continue
+		}
+
+		columnTypes, err := db.Migrator().ColumnTypes(target.table)
+		if err != nil {
+			return err
+		}
+		isDate := false
+		for _, columnType := range columnTypes {
+			if columnType.Name() == target.column {
+				isDate = strings.EqualFold(columnType.DatabaseTypeName(), "date")
+				break
+			}
+		}
+		if isDate {
+			continue
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			// 日時をユーザーのタイムゾーンでの日付（UTCの0時）に置き換えてから型を変更する
+			update := fmt.Sprintf(
+				`UPDATE %[1]s AS t SET %[2]s = ((t.%[2]s AT TIME ZONE COALESCE(NULLIF(u.timezone, ''), 'Asia/Tokyo'))::date)::timestamp AT TIME ZONE 'UTC' FROM "user" AS u WHERE u.id = t.user_id AND t.%[2]s IS NOT NULL`,
+				target.table, target.column,
+			)
+			if err := tx.Exec(update).Error; err != nil {
+				return err
+			}
+			alter := fmt.Sprintf(
+				`ALTER TABLE %[1]s ALTER COLUMN %[2]s TYPE date USING (%[2]s AT TIME ZONE 'UTC')::date`,
+				target.table, target.column,
+			)
+			return tx.Exec(alter).Error
+		})
+		if err != nil {
+			return fmt.Errorf("%s.%s: %w", target.table, target.column, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package clock
+
+import "time"
+
+// Clock は現在時刻を提供するインターフェース
+// サービスが time.Now() を直接呼ばないようにし、テストや動作確認で時刻を差し替えられるようにする
+type Clock interface {
+	Now() time.Time
+}
+
+// realClock はシステム時刻を返すClock
+type realClock struct{}
+
+// New はシステム時刻を返すClockを作成
+func New() Clock {
+	return realClock{}
+}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// FixedClock は任意の時刻を返すClock（テスト用）
+type FixedClock struct {
+	now time.Time
+}
+
+// NewFixed は指定した時刻を返すClockを作成
+func NewFixed(now time.Time) *FixedClock {
+	return &FixedClock{now: now}
+}
+
+func (c *FixedClock) Now() time.Time {
+	return c.now
+}
+
+// Set は返す時刻を変更する
+func (c *FixedClock) Set(now time.Time) {
+	c.now = now
+}
+
+// Advance は返す時刻を指定した時間だけ進める
+func (c *FixedClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
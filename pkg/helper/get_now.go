@@ -0,0 +1,18 @@
+package helper
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetNowFromContext はTimeTravelミドルウェアで差し替えられた日時を取得する
+func GetNowFromContext(c *gin.Context) (time.Time, bool) {
+	nowInterface, exists := c.Get("now")
+	if !exists {
+		return time.Time{}, false
+	}
+
+	now, ok := nowInterface.(time.Time)
+	return now, ok
+}